@@ -49,6 +49,7 @@ type Kinesis[T any] struct {
 	streamName string
 	done       chan struct{}
 	logger     zl.Logger
+	Backoff    streaming.Backoff
 }
 
 func New[T any](sess *session.Session, streamName string, logger *zl.Logger) *Kinesis[T] {
@@ -65,6 +66,7 @@ func New[T any](sess *session.Session, streamName string, logger *zl.Logger) *Ki
 		streamName: streamName,
 		done:       make(chan struct{}),
 		logger:     log,
+		Backoff:    streaming.DefaultBackoff,
 	}
 }
 
@@ -256,49 +258,63 @@ func (t *Kinesis[T]) PublishBatch(in <-chan T, batchSize int, timeout time.Durat
 		return b, nil
 	})
 
-	batchedStream := batchRecords(morphed, batchSize, timeout)
-	retryStream := make(chan []*kinesis.PutRecordsRequestEntry)
-	ch := streaming.Merge[[]*kinesis.PutRecordsRequestEntry](t.done, batchedStream, retryStream)
+	ctx, cancel := streaming.DoneContext(t.done)
+	defer cancel()
 
-	return t.publishBatch(ch, retryStream)
+	return t.publishBatch(ctx, batchRecords(morphed, batchSize, timeout))
 }
 
-func (t *Kinesis[T]) publishBatch(in <-chan []*kinesis.PutRecordsRequestEntry, retry chan<- []*kinesis.PutRecordsRequestEntry) error {
+// publishBatch put each batch retrying with t.Backoff, only the records
+// Kinesis rejected are sent again. Batches that still fail are logged and
+// dropped.
+func (t *Kinesis[T]) publishBatch(ctx context.Context, in <-chan []*kinesis.PutRecordsRequestEntry) error {
 	var count int64
 	for records := range in {
-		input := &kinesis.PutRecordsInput{
-			StreamName: aws.String(t.streamName),
-			Records:    records,
-		}
+		attempts, err := streaming.RetryFunc(ctx, t.Backoff, func(ctx context.Context) error {
+			output, err := t.srv.PutRecordsWithContext(ctx, &kinesis.PutRecordsInput{
+				StreamName: aws.String(t.streamName),
+				Records:    records,
+			})
+			if err != nil {
+				return err
+			}
 
-		output, err := t.srv.PutRecords(input)
+			count += int64(len(records)) - aws.Int64Value(output.FailedRecordCount)
+			records = failedRecords(records, output.Records)
+			if len(records) > 0 {
+				return fmt.Errorf("%d records failed", len(records))
+			}
+			return nil
+		})
 		if err != nil {
 			t.logger.Error().
 				Err(err).
+				Int("attempts", attempts).
 				Int("batch", len(records)).
 				Msg("failed publishing batch")
 			continue
 		}
 
-		failedRecords := aws.Int64Value(output.FailedRecordCount)
-
-		if failedRecords > 0 {
-			retry <- records
-			<-time.After(200 * time.Millisecond)
-		} else {
-			count += int64(len(output.Records))
-		}
-
 		t.logger.Info().
-			Int64("failed_records", failedRecords).
-			Int("records", len(output.Records)).
+			Int("attempts", attempts).
 			Int64("count", count).
-			Msg("published message")
+			Msg("published batch")
 	}
 
 	return nil
 }
 
+// failedRecords entries of records whose result has an error code
+func failedRecords(records []*kinesis.PutRecordsRequestEntry, results []*kinesis.PutRecordsResultEntry) []*kinesis.PutRecordsRequestEntry {
+	var failed []*kinesis.PutRecordsRequestEntry
+	for i, result := range results {
+		if i < len(records) && result.ErrorCode != nil {
+			failed = append(failed, records[i])
+		}
+	}
+	return failed
+}
+
 func batchRecords(in <-chan []byte, batchSize int, timeout time.Duration) <-chan []*kinesis.PutRecordsRequestEntry {
 
 	out := make(chan []*kinesis.PutRecordsRequestEntry)
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/sjhitchner/toolbox/pkg/metrics"
+	"github.com/sjhitchner/toolbox/pkg/streaming"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
@@ -18,6 +19,7 @@ type SQSClient[T any] struct {
 	BufferSize   int
 	BatchSize    int
 	BatchTimeout time.Duration
+	Backoff      streaming.Backoff
 	doneCh       <-chan struct{}
 	serializer   Serializer[T]
 }
@@ -26,12 +28,14 @@ func New[T any](done <-chan struct{}, cfg aws.Config, serializer Serializer[T],
 	client := sqs.NewFromConfig(cfg)
 
 	return &SQSClient[T]{
-		client:     client,
-		queueURL:   queueURL,
-		doneCh:     done,
-		BatchSize:  10,
-		BufferSize: 100,
-		serializer: serializer,
+		client:       client,
+		queueURL:     queueURL,
+		doneCh:       done,
+		BatchSize:    10,
+		BatchTimeout: time.Second,
+		BufferSize:   100,
+		Backoff:      streaming.DefaultBackoff,
+		serializer:   serializer,
 	}, nil
 }
 
@@ -39,12 +43,14 @@ func NewJSONQueue[T any](done <-chan struct{}, cfg aws.Config, queueURL string)
 	client := sqs.NewFromConfig(cfg)
 
 	return &SQSClient[T]{
-		client:     client,
-		queueURL:   queueURL,
-		doneCh:     done,
-		BatchSize:  10,
-		BufferSize: 100,
-		serializer: JSONSerializer[T]{},
+		client:       client,
+		queueURL:     queueURL,
+		doneCh:       done,
+		BatchSize:    10,
+		BatchTimeout: time.Second,
+		BufferSize:   100,
+		Backoff:      streaming.DefaultBackoff,
+		serializer:   JSONSerializer[T]{},
 	}, nil
 }
 
@@ -3,8 +3,6 @@ package sqs
 import (
 	"context"
 	"fmt"
-	"math"
-	"time"
 
 	"github.com/sjhitchner/toolbox/pkg/metrics"
 	"github.com/sjhitchner/toolbox/pkg/streaming"
@@ -14,10 +12,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 )
 
-const (
-	BackoffFactor = 1.5
-)
-
 func (t *SQSClient[T]) Send(workerCount int, inCh <-chan T) <-chan error {
 	serialChs := make([]<-chan string, workerCount)
 	errChs := make([]<-chan error, 2*workerCount)
@@ -38,8 +32,6 @@ func (t *SQSClient[T]) Send(workerCount int, inCh <-chan T) <-chan error {
 func (t *SQSClient[T]) sendLoop(inCh <-chan string) <-chan error {
 	errCh := make(chan error)
 
-	retryCh := make(chan string)
-
 	go func() {
 		defer close(errCh)
 
@@ -105,55 +97,36 @@ func (t *SQSClient[T]) SendBatch(workerCount int, inCh <-chan T) <-chan error {
 	return streaming.MergeDone(t.doneCh, errChs...)
 }
 
+// batchSendLoop send batches of messages, retrying each with t.Backoff. A
+// batch that still fails is reported on the error channel and dropped.
 func (t *SQSClient[T]) batchSendLoop(inCh <-chan string) <-chan error {
 	errCh := make(chan error)
 
-	retryCh := make(chan string)
-
 	go func() {
 		defer close(errCh)
-		defer close(retryCh)
 
-		var errCount float64
+		ctx, cancel := streaming.DoneContext(t.doneCh)
+		defer cancel()
+
+		for messages := range streaming.BatchContext(ctx, inCh, t.BatchSize, t.BatchTimeout) {
+			_, err := streaming.RetryFunc(ctx, t.Backoff, func(ctx context.Context) error {
+				return t.batchSend(ctx, messages)
+			})
+			if err == nil {
+				continue
+			}
 
-		for {
 			select {
-			case <-t.doneCh:
+			case errCh <- err:
+			case <-ctx.Done():
 				return
-
-			case messages, ok := <-streaming.Batch(t.doneCh, inCh, t.BatchSize, t.BatchTimeout):
-				if !ok {
-					return
-				}
-
-				if err := t.batchSend(messages); err != nil {
-					for _, message := range messages {
-						retryCh <- message
-					}
-					errCh <- err
-				}
-
-			case messages := <-streaming.Batch(t.doneCh, retryCh, t.BatchSize, t.BatchTimeout):
-				if err := t.batchSend(messages); err != nil {
-					for _, message := range messages {
-						retryCh <- message
-					}
-					errCh <- err
-
-					errCount++
-
-					backoff := 100 * math.Pow(BackoffFactor, errCount)
-					<-time.After(time.Duration(backoff) * time.Millisecond)
-				} else {
-					errCount = 0
-				}
 			}
 		}
 	}()
 	return errCh
 }
 
-func (t *SQSClient[T]) batchSend(messages []string) error {
+func (t *SQSClient[T]) batchSend(ctx context.Context, messages []string) error {
 	if len(messages) == 0 {
 		return nil
 	}
@@ -166,8 +139,6 @@ func (t *SQSClient[T]) batchSend(messages []string) error {
 		})
 	}
 
-	// TODO timeout
-	ctx := context.Background()
 	return t.batchSQSSend(ctx, entries)
 }
 
@@ -0,0 +1,141 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// Compression format of a compressed file
+type Compression int
+
+const (
+	NoCompression Compression = iota
+	Gzip
+	Zstd
+	Bzip2
+)
+
+var (
+	compressionExts = map[string]Compression{
+		".gz":   Gzip,
+		".gzip": Gzip,
+		".zst":  Zstd,
+		".zstd": Zstd,
+		".bz2":  Bzip2,
+	}
+
+	gzipMagic  = []byte{0x1f, 0x8b}
+	zstdMagic  = []byte{0x28, 0xb5, 0x2f, 0xfd}
+	bzip2Magic = []byte("BZh")
+)
+
+func (t Compression) String() string {
+	switch t {
+	case NoCompression:
+		return "none"
+	case Gzip:
+		return "gzip"
+	case Zstd:
+		return "zstd"
+	case Bzip2:
+		return "bzip2"
+	}
+	return "Compression(" + strconv.Itoa(int(t)) + ")"
+}
+
+// CompressionOf compression of filename from its extension
+func CompressionOf(filename string) Compression {
+	return compressionExts[strings.ToLower(filepath.Ext(filename))]
+}
+
+// TrimCompression filename without its compression extension, data.csv.gz
+// becomes data.csv
+func TrimCompression(filename string) string {
+	if CompressionOf(filename) == NoCompression {
+		return filename
+	}
+	return strings.TrimSuffix(filename, filepath.Ext(filename))
+}
+
+// sniffCompression compression from the magic bytes at the start of a file
+func sniffCompression(magic []byte) Compression {
+	switch {
+	case bytes.HasPrefix(magic, gzipMagic):
+		return Gzip
+	case bytes.HasPrefix(magic, zstdMagic):
+		return Zstd
+	case bytes.HasPrefix(magic, bzip2Magic):
+		return Bzip2
+	}
+	return NoCompression
+}
+
+// Decompress wrap r in a decompressor chosen by the extension of filename,
+// or by the magic bytes at the start of r if the extension is not one of
+// .gz, .zst or .bz2. Uncompressed input is read as is. Closing the result
+// closes r if it is an io.Closer.
+func Decompress(filename string, r io.Reader) (io.ReadCloser, error) {
+	source := r
+	closer := func() error {
+		if c, ok := source.(io.Closer); ok {
+			return c.Close()
+		}
+		return nil
+	}
+
+	compression := CompressionOf(filename)
+	if compression == NoCompression {
+		buffered := bufio.NewReader(r)
+
+		// Short files have no magic, Peek returns what there is
+		magic, _ := buffered.Peek(len(zstdMagic))
+		compression = sniffCompression(magic)
+		r = buffered
+	}
+
+	switch compression {
+	case Gzip:
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			closer()
+			return nil, err
+		}
+		return &readCloser{Reader: zr, close: func() error {
+			zr.Close()
+			return closer()
+		}}, nil
+
+	case Zstd:
+		zr, err := zstd.NewReader(r)
+		if err != nil {
+			closer()
+			return nil, err
+		}
+		return &readCloser{Reader: zr, close: func() error {
+			zr.Close()
+			return closer()
+		}}, nil
+
+	case Bzip2:
+		return &readCloser{Reader: bzip2.NewReader(r), close: closer}, nil
+	}
+
+	return &readCloser{Reader: r, close: closer}, nil
+}
+
+type readCloser struct {
+	io.Reader
+	close func() error
+}
+
+func (t *readCloser) Close() error {
+	return t.close()
+}
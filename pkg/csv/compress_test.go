@@ -0,0 +1,92 @@
+package csv
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+
+	"github.com/klauspost/compress/zstd"
+	. "gopkg.in/check.v1"
+)
+
+type CompressSuite struct{}
+
+var _ = Suite(&CompressSuite{})
+
+const compressCSV = "name,amount\nc,3\n"
+
+// bzip2Data compressCSV compressed with bzip2, the standard library only
+// decompresses
+var bzip2Data = []byte("\x42\x5a\x68\x39\x31\x41\x59\x26\x53\x59\xaf\x9b\x4e\x13\x00\x00\x05\xd9\x80\x00\x10\x00\x04\x08\x00\x2a\x03\x86\x00\x20\x00\x22\x06\x9a\x34\x08\x06\x9a\x68\xd0\x6c\xa8\xb4\x9a\x78\x1e\xf1\x77\x24\x53\x85\x09\x0a\xf9\xb4\xe1\x30")
+
+func gzipData(c *C, data string) []byte {
+	buf := &bytes.Buffer{}
+	w := gzip.NewWriter(buf)
+	_, err := w.Write([]byte(data))
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(), IsNil)
+	return buf.Bytes()
+}
+
+func zstdData(c *C, data string) []byte {
+	buf := &bytes.Buffer{}
+	w, err := zstd.NewWriter(buf)
+	c.Assert(err, IsNil)
+	_, err = w.Write([]byte(data))
+	c.Assert(err, IsNil)
+	c.Assert(w.Close(), IsNil)
+	return buf.Bytes()
+}
+
+type closeRecorder struct {
+	io.Reader
+	closed bool
+}
+
+func (t *closeRecorder) Close() error {
+	t.closed = true
+	return nil
+}
+
+func (s *CompressSuite) decompress(c *C, filename string, data []byte) string {
+	source := &closeRecorder{Reader: bytes.NewReader(data)}
+
+	reader, err := Decompress(filename, source)
+	c.Assert(err, IsNil)
+
+	b, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+
+	c.Assert(reader.Close(), IsNil)
+	c.Assert(source.closed, Equals, true)
+	return string(b)
+}
+
+func (s *CompressSuite) Test_Decompress_Extension(c *C) {
+	c.Assert(s.decompress(c, "a.csv.gz", gzipData(c, compressCSV)), Equals, compressCSV)
+	c.Assert(s.decompress(c, "a.csv.zst", zstdData(c, compressCSV)), Equals, compressCSV)
+	c.Assert(s.decompress(c, "a.csv.bz2", bzip2Data), Equals, compressCSV)
+}
+
+func (s *CompressSuite) Test_Decompress_Magic(c *C) {
+	c.Assert(s.decompress(c, "a.csv", gzipData(c, compressCSV)), Equals, compressCSV)
+	c.Assert(s.decompress(c, "a", zstdData(c, compressCSV)), Equals, compressCSV)
+	c.Assert(s.decompress(c, "", bzip2Data), Equals, compressCSV)
+	c.Assert(s.decompress(c, "a.csv", []byte(compressCSV)), Equals, compressCSV)
+	c.Assert(s.decompress(c, "a.csv", []byte("a")), Equals, "a")
+}
+
+func (s *CompressSuite) Test_Decompress_Invalid(c *C) {
+	_, err := Decompress("a.csv.gz", bytes.NewReader([]byte(compressCSV)))
+	c.Assert(err, NotNil)
+}
+
+func (s *CompressSuite) Test_TrimCompression(c *C) {
+	c.Assert(TrimCompression("data.csv.gz"), Equals, "data.csv")
+	c.Assert(TrimCompression("data.csv.ZST"), Equals, "data.csv")
+	c.Assert(TrimCompression("data.csv"), Equals, "data.csv")
+	c.Assert(CompressionOf("data.tsv.bz2"), Equals, Bzip2)
+	c.Assert(IsCSV("data.csv.bz2"), Equals, true)
+	c.Assert(IsCSV("data.json.gz"), Equals, false)
+	c.Assert(IsTSV("data.tsv.gz"), Equals, true)
+}
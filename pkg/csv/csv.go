@@ -1,19 +1,17 @@
 package csv
 
 import (
+	"bufio"
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"io/fs"
-	"os"
 	"path/filepath"
 	"reflect"
 	"strconv"
-	"time"
 
-	"github.com/pkg/errors"
 	zl "github.com/rs/zerolog"
-	"github.com/sjhitchner/toolbox/pkg/streaming"
 )
 
 const (
@@ -25,7 +23,7 @@ const (
 
 // IsCSV FilterFunc implementation
 func IsCSV(filename string) bool {
-	ext := filepath.Ext(filename)
+	ext := filepath.Ext(TrimCompression(filename))
 	if ext != ".txt" && ext != ".csv" {
 		return false
 	}
@@ -35,7 +33,7 @@ func IsCSV(filename string) bool {
 
 // IsTSV FilterFunc implementation
 func IsTSV(filename string) bool {
-	ext := filepath.Ext(filename)
+	ext := filepath.Ext(TrimCompression(filename))
 	if ext != ".txt" && ext != ".tsv" {
 		return false
 	}
@@ -43,10 +41,37 @@ func IsTSV(filename string) bool {
 
 }
 
+// Reader decodes CSV rows into structs of type T. Rows that fail to parse
+// are reported as *ParseError, ErrorPolicy decides whether reading carries
+// on, by default SkipAndReport. MaxErrors bounds the Collect policy.
+//
+// With a header, fields match columns by tag name or alias, ignoring case
+// if IgnoreCase is set. Strict rejects a header that is missing a field not
+// tagged optional, DisallowUnknown rejects one with columns no field reads,
+// both report a *HeaderError and read nothing.
+//
+// Filter selects the files Walk reads from directories. Workers, ChunkSize
+// and Ordered configure StreamParallel.
+//
+// SkipRows lines are discarded before the header or first row, lines
+// starting with Comment are ignored if it is set and LazyQuotes allows
+// quotes in unquoted fields, as encoding/csv does.
 type Reader[T any] struct {
-	Comma      rune
-	DateFormat string
-	HasHeader  bool
+	Comma           rune
+	DateFormat      string
+	HasHeader       bool
+	ErrorPolicy     ErrorPolicy
+	MaxErrors       int
+	Strict          bool
+	DisallowUnknown bool
+	IgnoreCase      bool
+	Filter          FilterFunc
+	Workers         int
+	ChunkSize       int
+	Ordered         bool
+	Comment         rune
+	SkipRows        int
+	LazyQuotes      bool
 }
 
 func NewReader[T any]() *Reader[T] {
@@ -54,114 +79,92 @@ func NewReader[T any]() *Reader[T] {
 		Comma:      ',',
 		DateFormat: defaultDateFormat,
 		HasHeader:  false,
+		Filter:     IsCSV,
 	}
 }
 
-func (t Reader[T]) Walk(path string) (<-chan T, <-chan error) {
-
-	fileCh := make(chan string)
-	outCh := make(chan T)
-	errCh := make(chan error)
-	readerErrCh := make(chan error)
-
-	go func() {
-		defer close(fileCh)
-		defer close(readerErrCh)
-
-		if err := filepath.WalkDir(path, func(filename string, d fs.DirEntry, err error) error {
-
-			if d.IsDir() {
-				return nil
-			}
-
-			fileCh <- filename
-
-			return nil
-
-		}); err != nil {
-			readerErrCh <- err
-		}
-	}()
-
-	go func() {
-		defer close(outCh)
-		defer close(errCh)
-
-		for filename := range fileCh {
-			reader, err := os.Open(filename)
-			if err != nil {
-				errCh <- err
-				continue
-			}
-
-			ch, eCh, err := t.Stream(reader)
-			if err != nil {
-				errCh <- err
-				continue
-			}
-
-			streaming.Copy(outCh, ch)
-			streaming.Copy(errCh, eCh)
-		}
-	}()
-
-	return outCh, streaming.Merge(errCh, readerErrCh)
-}
-
 // Stream reads a CSV file and returns a channel of the generic type T representing each row,
 // and a channel for errors.
 func (t *Reader[T]) Stream(reader io.Reader) (<-chan T, <-chan error, error) {
+	return t.StreamFile("", reader)
+}
+
+// StreamFile like Stream, errors are reported against filename
+func (t *Reader[T]) StreamFile(filename string, reader io.Reader) (<-chan T, <-chan error, error) {
 	outCh := make(chan T)
 	errCh := make(chan error, 1) // Buffered to avoid blocking if the reader isn't ready
 
-	var obj T
-	value := reflect.ValueOf(obj)
-
-	for value.Kind() != reflect.Struct {
-		return nil, nil, fmt.Errorf("Type T is not a struct")
+	typ, err := structType[T]()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	typ := value.Type()
-
 	go func() {
 		defer close(outCh)
 		defer close(errCh)
 
-		headerMap := make(map[string]int)
-		reader := csv.NewReader(reader)
+		report := t.reporter(filename, errCh)
+
+		input, err := skipLines(reader, t.SkipRows)
+		if err != nil {
+			errCh <- readError(filename, 1, err)
+			return
+		}
+
+		var header []string
+		reader := t.newCSVReader(input)
 
 		// Skip header row if present
 		if t.HasHeader {
-			header, err := reader.Read()
+			var err error
+			header, err = reader.Read()
 			if err != nil {
 				errCh <- fmt.Errorf("error reading header: %w", err)
 				return
 			}
+		}
 
-			for i, key := range header {
-				headerMap[key] = i
-			}
+		decoder, herr := t.newRowDecoder(typ, header)
+		if herr != nil {
+			herr.File = filename
+			errCh <- herr
+			return
 		}
+		unique := uniqueValues{}
 
-		for {
+		for line := 1; ; line++ {
 			row, err := reader.Read()
 			if err != nil {
 				if err == io.EOF {
 					break // End of file
 				}
-				errCh <- fmt.Errorf("error reading record: %w", err)
-				return
+
+				readErr := readError(filename, line, err)
+				readErr.Line += t.SkipRows
+
+				var csvErr *csv.ParseError
+				if !errors.As(err, &csvErr) {
+					errCh <- readErr
+					return
+				}
+				if report(readErr) {
+					return
+				}
+				continue
 			}
+			line, _ = reader.FieldPos(0)
 
-			var obj T
-			if t.HasHeader {
-				obj, err = t.unmarshalNameTag(typ, headerMap, row)
-			} else {
-				obj, err = t.unmarshalNumTag(typ, row)
+			obj, errs := decoder.decode(row, line+t.SkipRows)
+			if len(errs) == 0 {
+				errs = decoder.checkUnique(obj, row, line+t.SkipRows, unique)
 			}
 
-			if err != nil {
-				errCh <- err
+			if len(errs) > 0 {
+				for _, err := range errs {
+					if report(err) {
+						return
+					}
+				}
 				continue
 			}
 
@@ -172,51 +175,167 @@ func (t *Reader[T]) Stream(reader io.Reader) (<-chan T, <-chan error, error) {
 	return outCh, errCh, nil
 }
 
-func (t *Reader[T]) unmarshalNumTag(typ reflect.Type, row []string) (T, error) {
-	var obj T
-	value := reflect.New(typ).Elem()
+// newCSVReader csv reader with the reader's dialect settings
+func (t *Reader[T]) newCSVReader(reader io.Reader) *csv.Reader {
+	r := csv.NewReader(reader)
+	if t.Comma != 0 {
+		r.Comma = t.Comma
+	}
+	r.Comment = t.Comment
+	r.LazyQuotes = t.LazyQuotes
+	return r
+}
 
-	// Iterate over fields in the struct
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		colTag := field.Tag.Get(CSVTag)
+// skipLines discard the first n lines of reader
+func skipLines(reader io.Reader, n int) (io.Reader, error) {
+	if n <= 0 {
+		return reader, nil
+	}
 
-		colIndex, err := strconv.Atoi(colTag)
-		if err != nil {
-			return obj, fmt.Errorf("csvTag should be an int %v", err)
+	buffered := bufio.NewReader(reader)
+	for i := 0; i < n; i++ {
+		for {
+			_, err := buffered.ReadSlice('\n')
+			if err == nil {
+				break
+			}
+			if errors.Is(err, bufio.ErrBufferFull) {
+				continue
+			}
+			if errors.Is(err, io.EOF) {
+				return buffered, nil
+			}
+			return nil, err
 		}
+	}
+	return buffered, nil
+}
 
-		if colIndex >= len(row) {
-			continue
+// structType reflect type of T, an error if T is not a struct or has an
+// invalid validate tag
+func structType[T any]() (reflect.Type, error) {
+	var obj T
+	typ := reflect.TypeOf(obj)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Type T is not a struct")
+	}
+
+	if err := rulesError(cachedFieldSpecs(typ)); err != nil {
+		return nil, err
+	}
+	return typ, nil
+}
+
+// reporter sends ParseErrors to errCh applying the reader's ErrorPolicy,
+// the returned function returns true if reading should stop
+func (t *Reader[T]) reporter(filename string, errCh chan<- error) func(*ParseError) bool {
+	limit := &errorLimit{policy: t.ErrorPolicy, max: t.MaxErrors}
+
+	return func(err *ParseError) bool {
+		err.File = filename
+		errCh <- err
+
+		if limit.add() {
+			if t.ErrorPolicy == Collect {
+				errCh <- ErrTooManyErrors
+			}
+			return true
 		}
+		return false
+	}
+}
 
-		if err := t.setField(&value, field, i, row[colIndex]); err != nil {
-			return obj, err
+// rowDecoder decodes rows into T using the cached field plan of T, columns
+// are matched by header name if the reader has a header and by the
+// numeric tags otherwise
+type rowDecoder[T any] struct {
+	reader  *Reader[T]
+	typ     reflect.Type
+	specs   []fieldSpec
+	header  []string
+	columns []int
+}
+
+// newRowDecoder match header to the fields of typ, returns a *HeaderError if
+// the header fails the reader's strict settings
+func (t *Reader[T]) newRowDecoder(typ reflect.Type, header []string) (*rowDecoder[T], *HeaderError) {
+	decoder := &rowDecoder[T]{
+		reader: t,
+		typ:    typ,
+		specs:  cachedFieldSpecs(typ),
+		header: header,
+	}
+
+	if t.HasHeader {
+		var unknown []string
+		decoder.columns, unknown = matchHeader(header, decoder.specs, t.IgnoreCase)
+
+		if herr := validateHeader(decoder.specs, decoder.columns, unknown, t.Strict, t.DisallowUnknown); herr != nil {
+			return nil, herr
 		}
 	}
+	return decoder, nil
+}
 
-	return value.Interface().(T), nil
+// decode row, which starts on line. Parse errors and violations of validate
+// rules other than unique are returned without the file.
+func (t *rowDecoder[T]) decode(row []string, line int) (T, []*ParseError) {
+	var obj T
+	var parseErr *ParseError
+	if t.reader.HasHeader {
+		obj, parseErr = t.reader.unmarshalNameTag(t.typ, t.specs, t.columns, row)
+	} else {
+		obj, parseErr = t.reader.unmarshalNumTag(t.typ, t.specs, row)
+	}
+
+	var errs []*ParseError
+	if parseErr != nil {
+		errs = []*ParseError{parseErr}
+	} else {
+		errs = t.validate(obj, row)
+	}
+
+	t.locate(errs, line)
+	return obj, errs
+}
+
+// checkUnique violations of unique rules by obj decoded from row, rows must
+// be checked in file order
+func (t *rowDecoder[T]) checkUnique(obj T, row []string, line int, unique uniqueValues) []*ParseError {
+	errs := t.validateUnique(obj, row, unique)
+	t.locate(errs, line)
+	return errs
+}
+
+// locate set the line and column name of errs
+func (t *rowDecoder[T]) locate(errs []*ParseError, line int) {
+	for _, err := range errs {
+		err.Line = line
+		if err.Column == "" && err.column >= 0 {
+			err.Column = columnName(t.header, err.column)
+		}
+	}
 }
 
-func (t *Reader[T]) unmarshalNameTag(typ reflect.Type, headerMap map[string]int, row []string) (T, error) {
+func (t *Reader[T]) unmarshalNumTag(typ reflect.Type, specs []fieldSpec, row []string) (T, *ParseError) {
 	var obj T
 	value := reflect.New(typ).Elem()
 
 	// Iterate over fields in the struct
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-		colTag := field.Tag.Get(CSVTag)
-
-		colIndex, found := headerMap[colTag]
-		if !found {
-			continue
+	for _, spec := range specs {
+		if spec.column < 0 {
+			return obj, &ParseError{
+				Field:  spec.field,
+				Err:    fmt.Errorf("csvTag should be an int"),
+				column: -1,
+			}
 		}
 
-		if colIndex >= len(row) {
+		if spec.column >= len(row) {
 			continue
 		}
 
-		if err := t.setField(&value, field, i, row[colIndex]); err != nil {
+		if err := t.setField(value, spec, spec.column, row[spec.column]); err != nil {
 			return obj, err
 		}
 	}
@@ -224,60 +343,59 @@ func (t *Reader[T]) unmarshalNameTag(typ reflect.Type, headerMap map[string]int,
 	return value.Interface().(T), nil
 }
 
-func (t *Reader[T]) setField(v *reflect.Value, field reflect.StructField, i int, str string) error {
-	switch field.Type.Kind() {
-	case reflect.Bool:
-		b, err := strconv.ParseBool(str)
-		if err != nil {
-			return errors.Wrapf(err, "invalid string float %v", v)
-		}
-		v.Field(i).SetBool(b)
+func (t *Reader[T]) unmarshalNameTag(typ reflect.Type, specs []fieldSpec, columns []int, row []string) (T, *ParseError) {
+	var obj T
+	value := reflect.New(typ).Elem()
 
-	case reflect.Float32, reflect.Float64:
-		f, err := strconv.ParseFloat(str, 64)
-		if err != nil {
-			return errors.Wrapf(err, "invalid string float %v", v)
+	// Iterate over fields in the struct
+	for i, spec := range specs {
+		colIndex := columns[i]
+		if colIndex < 0 || colIndex >= len(row) {
+			continue
 		}
-		v.Field(i).SetFloat(f)
-
-	case reflect.String:
-		fmt.Println("string", str)
-		v.Field(i).SetString(str)
 
-	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
-		n, err := strconv.ParseInt(str, 10, 64)
-		if err != nil {
-			return errors.Wrapf(err, "invalid string int64 %v", v)
+		if err := t.setField(value, spec, colIndex, row[colIndex]); err != nil {
+			return obj, err
 		}
-		v.Field(i).SetInt(n)
+	}
 
-	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
-		uintValue, err := strconv.ParseUint(str, 10, 64)
-		if err != nil {
-			return err
-		}
-		v.Field(i).SetUint(uintValue)
+	return value.Interface().(T), nil
+}
 
-	case reflect.Struct:
-		switch field.Type.Name() {
-		case "Time":
-			format := t.DateFormat
+// setField parse str into the field of v described by spec. Pointer fields
+// are nil for an empty column, sql.Null* types are not valid, types
+// implementing CSVUnmarshaler or encoding.TextUnmarshaler parse themselves
+// and slices are split on the sep tag option. Optional fields are left
+// zero for an empty column.
+func (t *Reader[T]) setField(v reflect.Value, spec fieldSpec, column int, str string) *ParseError {
+	if str == "" && spec.optional {
+		return nil
+	}
 
-			date, err := time.Parse(format, str)
-			if err != nil {
-				return errors.Wrapf(err, "invalid string date %v", v)
-			}
+	format := spec.format
+	if format == "" {
+		format = t.DateFormat
+	}
 
-			v.Field(i).Set(reflect.ValueOf(date))
+	if err := setValue(fieldByIndex(v, spec.index), str, format, spec.sep); err != nil {
+		return &ParseError{
+			Field:  spec.field,
+			Value:  str,
+			Err:    err,
+			column: column,
 		}
-
-	default:
-		fmt.Println("None", field, field.Type.Name())
 	}
-
 	return nil
 }
 
+// columnName header name of column, or its number counting from 1
+func columnName(header []string, column int) string {
+	if column < len(header) {
+		return header[column]
+	}
+	return strconv.Itoa(column + 1)
+}
+
 /*
 // findColumnIndex finds the index of a column in the header by name, returns -1 if not found
 func findColumnIndex(header []string, colName string) int {
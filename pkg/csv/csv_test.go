@@ -78,9 +78,50 @@ func (s *CSVSuite) Test_Decode(c *C) {
 	c.Assert(rows[2].Date.Format(defaultDateFormat), Equals, "2021-03-03T00:00:00Z")
 }
 
+func (s *CSVSuite) Test_Decode_DateLayout(c *C) {
+	type layoutRow struct {
+		Option time.Time `csv:"date,format=2006-01-02"`
+		Quoted time.Time `csv:"day,format='Jan 2, 2006'"`
+		Legacy time.Time `csv:"Jan 2, 2006"`
+		Named  time.Time `csv:"at,optional"`
+	}
+
+	reader := csv.NewReader(strings.NewReader(`2021-01-01,"Feb 2, 2021","Mar 3, 2021",2021-04-04T00:00:00Z` + "\n"))
+
+	var rows []layoutRow
+	c.Assert(NewDecoder(reader).Decode(&rows), IsNil)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0].Option.Format(time.DateOnly), Equals, "2021-01-01")
+	c.Assert(rows[0].Quoted.Format(time.DateOnly), Equals, "2021-02-02")
+	c.Assert(rows[0].Legacy.Format(time.DateOnly), Equals, "2021-03-03")
+	c.Assert(rows[0].Named.Format(time.DateOnly), Equals, "2021-04-04")
+}
+
+func (s *CSVSuite) Test_Decode_Embedded(c *C) {
+	type Name struct {
+		Foo string
+	}
+
+	type embeddedRow struct {
+		Name
+		skipped string
+		Ignored string `csv:"-"`
+		Qwerty  string
+		Date    time.Time `csv:"2006-01-02"`
+	}
+
+	reader := csv.NewReader(strings.NewReader("foo1,qwerty1,2021-01-01\n"))
+
+	var rows []embeddedRow
+	c.Assert(NewDecoder(reader).Decode(&rows), IsNil)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0].Foo, Equals, "foo1")
+	c.Assert(rows[0].Qwerty, Equals, "qwerty1")
+	c.Assert(rows[0].Date.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)), Equals, true)
+}
+
 func (s *CSVSuite) Test_Stream_NumTag(c *C) {
-	reader := csv.NewReader(strings.NewReader(CSVNoHeader))
-	outCh, errCh, err := Stream[ColNumTag](reader, false)
+	outCh, errCh, err := NewReader[ColNumTag]().Stream(strings.NewReader(CSVNoHeader))
 	c.Assert(err, IsNil)
 
 	assertNoError(c, errCh)
@@ -102,8 +143,10 @@ func (s *CSVSuite) Test_Stream_NumTag(c *C) {
 }
 
 func (s *CSVSuite) Test_Stream_NameTag(c *C) {
-	reader := csv.NewReader(strings.NewReader(CSVHeader))
-	outCh, errCh, err := Stream[ColNameTag](reader, true)
+	reader := NewReader[ColNameTag]()
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader(CSVHeader))
 	c.Assert(err, IsNil)
 
 	assertNoError(c, errCh)
@@ -17,13 +17,11 @@ package csv
 
 import (
 	"encoding/csv"
+	"errors"
 	"fmt"
 	"io"
 	"reflect"
-	"strconv"
 	"time"
-
-	"github.com/pkg/errors"
 )
 
 const (
@@ -34,6 +32,10 @@ type Decoder struct {
 	reader     *csv.Reader
 	hasHeader  bool
 	dateFormat string
+	file       string
+	policy     ErrorPolicy
+	maxErrors  int
+	header     []string
 }
 
 func NewDecoder(reader *csv.Reader) *Decoder {
@@ -41,6 +43,7 @@ func NewDecoder(reader *csv.Reader) *Decoder {
 		reader:     reader,
 		hasHeader:  false,
 		dateFormat: defaultDateFormat,
+		policy:     FailFast,
 	}
 }
 
@@ -52,87 +55,166 @@ func (t *Decoder) SetDateFormat(format string) {
 	t.dateFormat = format
 }
 
+// SetFile name of the file being decoded, used in errors
+func (t *Decoder) SetFile(filename string) {
+	t.file = filename
+}
+
+// SetErrorPolicy how rows that fail to parse are handled, the default is
+// FailFast. maxErrors bounds the Collect policy.
+func (t *Decoder) SetErrorPolicy(policy ErrorPolicy, maxErrors int) {
+	t.policy = policy
+	t.maxErrors = maxErrors
+}
+
+// Decode append every row to rows, a pointer to a slice of structs. Fields
+// are matched to columns by position, fields of embedded structs count as
+// fields of the struct and unexported or "-" tagged fields are skipped. The
+// date format of a field is its format option, or the whole csv tag when it
+// has no options (see dateLayout). With FailFast the first *ParseError
+// is returned, otherwise rows that fail are skipped and their errors are
+// returned together as ParseErrors. Rows that violate a validate rule fail
+// with an error for each violation.
 func (t *Decoder) Decode(rows interface{}) error {
+	typ := reflect.TypeOf(rows)
+	if typ == nil || typ.Kind() != reflect.Pointer ||
+		typ.Elem().Kind() != reflect.Slice ||
+		typ.Elem().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("rows must be a pointer to a slice of structs, not %v", typ)
+	}
+
+	if err := rulesError(cachedFieldSpecs(typ.Elem().Elem())); err != nil {
+		return err
+	}
+	unique := uniqueValues{}
+
+	limit := &errorLimit{policy: t.policy, max: t.maxErrors}
+	var errs ParseErrors
+
+	// fail returns the error Decode should return, nil to carry on
+	fail := func(err *ParseError) error {
+		if t.policy == FailFast {
+			return err
+		}
 
-	for i := 1; ; {
+		errs = append(errs, err)
+		if limit.add() {
+			return fmt.Errorf("%w: %w", ErrTooManyErrors, errs)
+		}
+		return nil
+	}
+
+	first := true
+	for line := 1; ; line++ {
 		row, err := t.reader.Read()
 		if err == io.EOF {
 			break
 		}
 
 		if err != nil {
-			return err
+			var csvErr *csv.ParseError
+			if !errors.As(err, &csvErr) {
+				return readError(t.file, line, err)
+			}
+			if err := fail(readError(t.file, line, err)); err != nil {
+				return err
+			}
+			continue
 		}
+		line, _ = t.reader.FieldPos(0)
 
-		if t.hasHeader && i == 1 {
+		if t.hasHeader && first {
+			first = false
+			t.header = row
 			continue
 		}
+		first = false
 
-		if err := t.parseRow(rows, row); err != nil {
-			return errors.Wrapf(err, "error parsing row %d", i)
+		for _, err := range t.parseRow(rows, row, unique) {
+			err.File = t.file
+			err.Line = line
+			if err := fail(err); err != nil {
+				return err
+			}
 		}
 	}
 
+	if len(errs) > 0 {
+		return errs
+	}
 	return nil
 }
 
-func (t *Decoder) parseRow(arrPtr interface{}, row []string) error {
+// dateLayout date format of spec, its format option if set. A tag without
+// options is a layout on its own, as tags were before options existed, so
+// `csv:"Jan 2, 2006"` still works. Otherwise the decoder's date format.
+func (t *Decoder) dateLayout(spec fieldSpec) string {
+	switch {
+	case spec.format != "":
+		return spec.format
+	case spec.tag != "" && !spec.options:
+		return spec.tag
+	}
+	return t.dateFormat
+}
+
+// parseRow append row to arrPtr unless it fails to parse or violates a
+// validate rule, unique holds the values of earlier rows
+func (t *Decoder) parseRow(arrPtr interface{}, row []string, unique uniqueValues) []*ParseError {
 
 	typ := reflect.TypeOf(arrPtr).Elem().Elem()
 	v := reflect.New(typ).Elem()
 
 	arr := reflect.ValueOf(arrPtr).Elem()
 
-	for i := 0; i < typ.NumField(); i++ {
-		field := typ.Field(i)
-
-		switch field.Type.Name() {
-
-		case "bool":
-			b, err := strconv.ParseBool(row[i])
-			if err != nil {
-				return errors.Wrapf(err, "invalid string float %v", v)
-			}
-
-			v.Field(i).SetBool(b)
-
-		case "float64":
-			f, err := strconv.ParseFloat(row[i], 64)
-			if err != nil {
-				return errors.Wrapf(err, "invalid string float %v", v)
-			}
-
-			v.Field(i).SetFloat(f)
-
-		case "string":
-			v.Field(i).SetString(row[i])
+	specs := cachedFieldSpecs(typ)
+	for i, spec := range specs {
+		if i >= len(row) {
+			return []*ParseError{{
+				Column: columnName(t.header, i),
+				Field:  spec.field,
+				Err:    ErrMissingColumn,
+			}}
+		}
 
-		case "int64":
-			n, err := strconv.ParseInt(row[i], 10, 64)
-			if err != nil {
-				return errors.Wrapf(err, "invalid string int %v", v)
-			}
+		if err := setValue(fieldByIndex(v, spec.index), row[i], t.dateLayout(spec), DefaultSeparator); err != nil {
+			return []*ParseError{{
+				Column: columnName(t.header, i),
+				Field:  spec.field,
+				Value:  row[i],
+				Err:    err,
+			}}
+		}
+	}
 
-			v.Field(i).SetInt(n)
+	// violation error for the value of column i
+	violation := func(i int, err *Violation) *ParseError {
+		return &ParseError{
+			Column: columnName(t.header, i),
+			Field:  specs[i].field,
+			Value:  row[i],
+			Err:    err,
+		}
+	}
 
-		case "Time":
-			format := field.Tag.Get("csv")
-			if format == "" {
-				format = t.dateFormat
-			}
+	var errs []*ParseError
+	for i, spec := range specs {
+		for _, err := range checkRules(spec.rules, row[i], fieldByIndexRead(v, spec.index)) {
+			errs = append(errs, violation(i, err))
+		}
+	}
 
-			date, err := time.Parse(format, row[i])
-			if err != nil {
-				return errors.Wrapf(err, "invalid string date %v", v)
+	// Values of rows that fail are not recorded as seen
+	if len(errs) == 0 {
+		for i, spec := range specs {
+			if hasRule(spec.rules, "unique") && unique.seen(i, row[i], fieldByIndexRead(v, spec.index)) {
+				errs = append(errs, violation(i, &Violation{Rule: "unique"}))
 			}
-
-			v.Field(i).Set(reflect.ValueOf(date))
-
-		default:
-			fmt.Println("None", field, field.Type.Name())
-
 		}
 	}
+	if len(errs) > 0 {
+		return errs
+	}
 
 	arr.Set(reflect.Append(arr, v))
 
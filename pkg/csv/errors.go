@@ -0,0 +1,147 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ErrorPolicy what a reader does when a row fails to parse
+type ErrorPolicy int
+
+const (
+	// SkipAndReport skip rows that fail and report every error, the Reader
+	// default
+	SkipAndReport ErrorPolicy = iota
+
+	// FailFast stop at the first error, the Decoder default
+	FailFast
+
+	// Collect skip rows that fail and report errors until MaxErrors have
+	// been seen, then stop
+	Collect
+)
+
+var (
+	// ErrTooManyErrors reported when a Collect policy reaches MaxErrors
+	ErrTooManyErrors = errors.New("too many errors")
+
+	// ErrMissingColumn row has fewer columns than the struct has fields
+	ErrMissingColumn = errors.New("missing column")
+)
+
+func (t ErrorPolicy) String() string {
+	switch t {
+	case FailFast:
+		return "FailFast"
+	case SkipAndReport:
+		return "SkipAndReport"
+	case Collect:
+		return "Collect"
+	}
+	return "ErrorPolicy(" + strconv.Itoa(int(t)) + ")"
+}
+
+// ParseError a cell or row that could not be parsed. Line is the line in
+// the file the row starts on. Column is the header name of the column, or
+// its number counting from 1 when there is no header, and Field the struct
+// field it was parsed into. Column, Field and Value are empty for errors
+// that affect the whole row.
+type ParseError struct {
+	File   string
+	Line   int
+	Column string
+	Field  string
+	Value  string
+	Err    error
+
+	column int
+}
+
+func (t *ParseError) Error() string {
+	buf := &strings.Builder{}
+
+	if t.File != "" {
+		fmt.Fprintf(buf, "%s:", t.File)
+	}
+	fmt.Fprintf(buf, "%d: ", t.Line)
+
+	if t.Column != "" {
+		fmt.Fprintf(buf, "column %q ", t.Column)
+	}
+	if t.Field != "" {
+		fmt.Fprintf(buf, "field %s ", t.Field)
+	}
+	if t.Column != "" || t.Field != "" {
+		fmt.Fprintf(buf, "value %q: ", t.Value)
+	}
+
+	buf.WriteString(t.Err.Error())
+	return buf.String()
+}
+
+func (t *ParseError) Unwrap() error {
+	return t.Err
+}
+
+// ParseErrors every error seen by a reader that does not fail fast
+type ParseErrors []*ParseError
+
+func (t ParseErrors) Error() string {
+	switch len(t) {
+	case 0:
+		return "no errors"
+	case 1:
+		return t[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", t[0].Error(), len(t)-1)
+}
+
+// Unwrap the individual errors, for errors.Is and errors.As
+func (t ParseErrors) Unwrap() []error {
+	errs := make([]error, len(t))
+	for i, err := range t {
+		errs[i] = err
+	}
+	return errs
+}
+
+// readError convert an error reading a row into a ParseError
+func readError(file string, line int, err error) *ParseError {
+	var csvErr *csv.ParseError
+	if errors.As(err, &csvErr) {
+		return &ParseError{
+			File: file,
+			Line: csvErr.Line,
+			Err:  csvErr.Err,
+		}
+	}
+
+	return &ParseError{
+		File: file,
+		Line: line,
+		Err:  err,
+	}
+}
+
+// errorLimit applies an ErrorPolicy to a stream of errors
+type errorLimit struct {
+	policy ErrorPolicy
+	max    int
+	count  int
+}
+
+// add record an error, returns true if reading should stop
+func (t *errorLimit) add() bool {
+	t.count++
+
+	switch t.policy {
+	case SkipAndReport:
+		return false
+	case Collect:
+		return t.max > 0 && t.count >= t.max
+	}
+	return true
+}
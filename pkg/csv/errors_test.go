@@ -0,0 +1,171 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type ErrorsSuite struct{}
+
+var _ = Suite(&ErrorsSuite{})
+
+type amountRow struct {
+	Name   string `csv:"name"`
+	Amount int    `csv:"amount"`
+}
+
+const badAmounts = `name,amount
+a,1
+b,x
+c,3
+d,y
+e,z
+`
+
+func streamAmounts(c *C, policy ErrorPolicy, max int) ([]amountRow, []error) {
+	reader := NewReader[amountRow]()
+	reader.HasHeader = true
+	reader.ErrorPolicy = policy
+	reader.MaxErrors = max
+
+	outCh, errCh, err := reader.StreamFile("amounts.csv", strings.NewReader(badAmounts))
+	c.Assert(err, IsNil)
+
+	return drain(outCh, errCh)
+}
+
+// drain read rows and errors until both channels close
+func drain[T any](outCh <-chan T, errCh <-chan error) ([]T, []error) {
+	var rows []T
+	var errs []error
+	for outCh != nil || errCh != nil {
+		select {
+		case row, ok := <-outCh:
+			if !ok {
+				outCh = nil
+				continue
+			}
+			rows = append(rows, row)
+		case err, ok := <-errCh:
+			if !ok {
+				errCh = nil
+				continue
+			}
+			errs = append(errs, err)
+		}
+	}
+	return rows, errs
+}
+
+func (s *ErrorsSuite) Test_ParseError(c *C) {
+	_, errs := streamAmounts(c, SkipAndReport, 0)
+	c.Assert(errs, HasLen, 3)
+
+	var parseErr *ParseError
+	c.Assert(errors.As(errs[0], &parseErr), Equals, true)
+	c.Assert(parseErr.File, Equals, "amounts.csv")
+	c.Assert(parseErr.Line, Equals, 3)
+	c.Assert(parseErr.Column, Equals, "amount")
+	c.Assert(parseErr.Field, Equals, "Amount")
+	c.Assert(parseErr.Value, Equals, "x")
+	c.Assert(parseErr, ErrorMatches, `amounts.csv:3: column "amount" field Amount value "x": invalid string int x.*`)
+}
+
+func (s *ErrorsSuite) Test_DefaultPolicy(c *C) {
+	var reader Reader[amountRow]
+	c.Assert(reader.ErrorPolicy, Equals, SkipAndReport)
+	c.Assert(NewReader[amountRow]().ErrorPolicy, Equals, SkipAndReport)
+	c.Assert(NewDecoder(nil).policy, Equals, FailFast)
+}
+
+func (s *ErrorsSuite) Test_Policy(c *C) {
+	rows, errs := streamAmounts(c, FailFast, 0)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(errs, HasLen, 1)
+
+	rows, errs = streamAmounts(c, SkipAndReport, 0)
+	c.Assert(rows, HasLen, 2)
+	c.Assert(errs, HasLen, 3)
+
+	rows, errs = streamAmounts(c, Collect, 2)
+	c.Assert(rows, HasLen, 2)
+	c.Assert(errs, HasLen, 3)
+	c.Assert(errs[2], Equals, ErrTooManyErrors)
+}
+
+func (s *ErrorsSuite) Test_ReadError(c *C) {
+	reader := NewReader[amountRow]()
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader("name,amount\na,1\nb,2,3\nc,3\n"))
+	c.Assert(err, IsNil)
+
+	// The malformed row is reported and skipped
+	rows, errs := drain(outCh, errCh)
+	c.Assert(rows, HasLen, 2)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, "3: wrong number of fields")
+	c.Assert(errors.Is(errs[0], csv.ErrFieldCount), Equals, true)
+}
+
+func (s *ErrorsSuite) Test_Decoder_MissingColumn(c *C) {
+	decoder := NewDecoder(csv.NewReader(strings.NewReader("foo1,qwerty1\n")))
+	decoder.reader.FieldsPerRecord = -1
+	decoder.SetFile("rows.csv")
+
+	var rows []Row
+	err := decoder.Decode(&rows)
+
+	var parseErr *ParseError
+	c.Assert(errors.As(err, &parseErr), Equals, true)
+	c.Assert(parseErr.Field, Equals, "Date")
+	c.Assert(parseErr.Column, Equals, "3")
+	c.Assert(errors.Is(err, ErrMissingColumn), Equals, true)
+	c.Assert(err, ErrorMatches, `rows.csv:1: column "3" field Date value "": missing column`)
+}
+
+func (s *ErrorsSuite) Test_Decoder_Policy(c *C) {
+	const data = `name,amount
+a,1
+b,x
+c,3
+d,y
+`
+	var rows []amountRow
+	decoder := NewDecoder(csv.NewReader(strings.NewReader(data)))
+	decoder.HasHeader()
+	c.Assert(decoder.Decode(&rows), ErrorMatches, `3: column "amount" .*`)
+	c.Assert(rows, HasLen, 1)
+
+	// Skipping is opt in
+	rows = nil
+	decoder = NewDecoder(csv.NewReader(strings.NewReader(data)))
+	decoder.HasHeader()
+	decoder.SetErrorPolicy(SkipAndReport, 0)
+
+	err := decoder.Decode(&rows)
+	c.Assert(rows, HasLen, 2)
+
+	var errs ParseErrors
+	c.Assert(errors.As(err, &errs), Equals, true)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[1].Line, Equals, 5)
+
+	rows = nil
+	decoder = NewDecoder(csv.NewReader(strings.NewReader(data)))
+	decoder.HasHeader()
+	decoder.SetErrorPolicy(Collect, 1)
+
+	err = decoder.Decode(&rows)
+	c.Assert(errors.Is(err, ErrTooManyErrors), Equals, true)
+	c.Assert(rows, HasLen, 1)
+}
+
+func (s *ErrorsSuite) Test_Decoder_NotSlice(c *C) {
+	var row Row
+	err := NewDecoder(csv.NewReader(strings.NewReader(""))).Decode(&row)
+	c.Assert(err, ErrorMatches, "rows must be a pointer to a slice of structs.*")
+}
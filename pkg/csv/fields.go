@@ -0,0 +1,416 @@
+package csv
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultSeparator splits a column into the elements of a slice field
+	DefaultSeparator = ";"
+)
+
+// CSVUnmarshaler is implemented by types that parse themselves from a column
+type CSVUnmarshaler interface {
+	UnmarshalCSV(string) error
+}
+
+// CSVMarshaler is implemented by types that format themselves as a column
+type CSVMarshaler interface {
+	MarshalCSV() (string, error)
+}
+
+var (
+	timeType           = reflect.TypeOf(time.Time{})
+	nullTimeType       = reflect.TypeOf(sql.NullTime{})
+	csvUnmarshalerType = reflect.TypeOf((*CSVUnmarshaler)(nil)).Elem()
+	textUnmarshalType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	scannerType        = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+// fieldSpec a struct field mapped to a column. The csv tag is the column
+// name or number followed by options, a value holding a comma is single
+// quoted. An optional column may be missing or empty, leaving the zero value.
+//
+//	Date   time.Time `csv:"date,format=2006-01-02"`
+//	Day    time.Time `csv:"day,format='Jan 2, 2006'"`
+//	Tags   []string  `csv:"tags,sep=|"`
+//	Pair   []int     `csv:"pair,sep=','"`
+//	Amount int       `csv:"amount,alias=amt|total"`
+//	Note   string    `csv:"note,optional"`
+type fieldSpec struct {
+	index    []int
+	field    string
+	tag      string
+	name     string
+	column   int
+	format   string
+	sep      string
+	aliases  []string
+	optional bool
+	options  bool
+	rules    []rule
+	rulesErr error
+}
+
+// parseTag split a csv tag into the column name and its options. An option
+// value starting with a single quote runs to the next one, so it may hold
+// commas, and the quotes are removed.
+func parseTag(tag string) (string, map[string]string) {
+	name, rest, _ := strings.Cut(tag, ",")
+
+	opts := make(map[string]string)
+	for rest != "" {
+		var key, value string
+
+		i := strings.IndexAny(rest, ",=")
+		if i < 0 || rest[i] == ',' {
+			key, rest, _ = strings.Cut(rest, ",")
+			opts[strings.TrimSpace(key)] = ""
+			continue
+		}
+		key, rest = rest[:i], rest[i+1:]
+
+		if quoted, ok := strings.CutPrefix(rest, "'"); ok {
+			value, rest, _ = strings.Cut(quoted, "'")
+			_, rest, _ = strings.Cut(rest, ",")
+		} else {
+			value, rest, _ = strings.Cut(rest, ",")
+		}
+		opts[strings.TrimSpace(key)] = value
+	}
+	return strings.TrimSpace(name), opts
+}
+
+// fieldSpecs the columns of typ in field order, fields of embedded structs
+// are included as if they were fields of typ
+func fieldSpecs(typ reflect.Type) []fieldSpec {
+	return appendFieldSpecs(nil, typ, nil)
+}
+
+// fieldPlans cache of fieldSpecs by struct type
+var fieldPlans sync.Map
+
+// cachedFieldSpecs fieldSpecs of typ, built once per type so tags are not
+// parsed for every file or chunk. The specs are shared and must not be
+// modified.
+func cachedFieldSpecs(typ reflect.Type) []fieldSpec {
+	if specs, found := fieldPlans.Load(typ); found {
+		return specs.([]fieldSpec)
+	}
+
+	specs, _ := fieldPlans.LoadOrStore(typ, fieldSpecs(typ))
+	return specs.([]fieldSpec)
+}
+
+func appendFieldSpecs(specs []fieldSpec, typ reflect.Type, parent []int) []fieldSpec {
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+
+		index := make([]int, len(parent)+1)
+		copy(index, parent)
+		index[len(parent)] = i
+
+		tag, hasTag := field.Tag.Lookup(CSVTag)
+		if tag == "-" {
+			continue
+		}
+
+		// Unexported embedded pointers cannot be allocated, like encoding/json
+		if field.Anonymous && !hasTag && isEmbeddedStruct(field.Type) {
+			if !field.IsExported() && field.Type.Kind() == reflect.Pointer {
+				continue
+			}
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			specs = appendFieldSpecs(specs, embedded, index)
+			continue
+		}
+
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts := parseTag(tag)
+
+		spec := fieldSpec{
+			index:  index,
+			field:  field.Name,
+			tag:    tag,
+			name:   name,
+			column: -1,
+			format: opts["format"],
+			sep:    opts["sep"],
+		}
+
+		if alias := opts["alias"]; alias != "" {
+			spec.aliases = strings.Split(alias, "|")
+		}
+		_, spec.optional = opts["optional"]
+		spec.options = spec.format != "" || opts["sep"] != "" || spec.aliases != nil || spec.optional
+
+		if rules, err := parseRules(field.Tag.Get(ValidateTag), field.Type); err != nil {
+			spec.rulesErr = fmt.Errorf("field %s: %w", field.Name, err)
+		} else {
+			spec.rules = rules
+		}
+
+		if n, err := strconv.Atoi(name); err == nil {
+			spec.column = n
+			spec.name = field.Name
+		} else if name == "" {
+			spec.name = field.Name
+		}
+
+		if spec.sep == "" {
+			spec.sep = DefaultSeparator
+		}
+
+		specs = append(specs, spec)
+	}
+
+	return specs
+}
+
+// isEmbeddedStruct whether an embedded field should be flattened rather
+// than treated as a single column
+func isEmbeddedStruct(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+
+	ptr := reflect.PointerTo(typ)
+	return !ptr.Implements(csvUnmarshalerType) &&
+		!ptr.Implements(textUnmarshalType) &&
+		!ptr.Implements(scannerType)
+}
+
+// fieldByIndex like reflect.Value.FieldByIndex but allocates nil embedded
+// struct pointers on the way
+func fieldByIndex(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// fieldByIndexRead like reflect.Value.FieldByIndex, returning an invalid
+// value if a nil embedded pointer is on the way
+func fieldByIndexRead(v reflect.Value, index []int) reflect.Value {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				return reflect.Value{}
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v
+}
+
+// setValue parse str into v, v must be settable
+func setValue(v reflect.Value, str string, format string, sep string) error {
+	if v.CanAddr() {
+		switch u := v.Addr().Interface().(type) {
+		case CSVUnmarshaler:
+			return u.UnmarshalCSV(str)
+
+		case sql.Scanner:
+			if str == "" {
+				return u.Scan(nil)
+			}
+
+			// Scan does not parse times from strings
+			if v.Type() == nullTimeType {
+				date, err := time.Parse(format, str)
+				if err != nil {
+					return errors.Wrapf(err, "invalid string date %v", str)
+				}
+				return u.Scan(date)
+			}
+			return u.Scan(str)
+		}
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if str == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		elem := reflect.New(v.Type().Elem())
+		if err := setValue(elem.Elem(), str, format, sep); err != nil {
+			return err
+		}
+		v.Set(elem)
+		return nil
+	}
+
+	if v.Type() == timeType {
+		date, err := time.Parse(format, str)
+		if err != nil {
+			return errors.Wrapf(err, "invalid string date %v", str)
+		}
+		v.Set(reflect.ValueOf(date))
+		return nil
+	}
+
+	if v.CanAddr() {
+		if u, ok := v.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(str))
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(str)
+		if err != nil {
+			return errors.Wrapf(err, "invalid string bool %v", str)
+		}
+		v.SetBool(b)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(str, v.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "invalid string float %v", str)
+		}
+		v.SetFloat(f)
+
+	case reflect.String:
+		v.SetString(str)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(str, 10, v.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "invalid string int %v", str)
+		}
+		v.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(str, 10, v.Type().Bits())
+		if err != nil {
+			return errors.Wrapf(err, "invalid string uint %v", str)
+		}
+		v.SetUint(n)
+
+	case reflect.Slice:
+		if str == "" {
+			v.Set(reflect.Zero(v.Type()))
+			return nil
+		}
+
+		parts := strings.Split(str, sep)
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setValue(slice.Index(i), part, format, sep); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+
+	default:
+		return fmt.Errorf("unsupported field type: %v", v.Type())
+	}
+
+	return nil
+}
+
+// formatValue format v as a column, the inverse of setValue
+func formatValue(v reflect.Value, format string, sep string) (string, error) {
+	// A nil pointer is an empty column, value receiver methods cannot be
+	// called on it
+	if !v.IsValid() || (v.Kind() == reflect.Pointer && v.IsNil()) {
+		return "", nil
+	}
+
+	if m, ok := asInterface[CSVMarshaler](v); ok {
+		return m.MarshalCSV()
+	}
+
+	if valuer, ok := asInterface[driver.Valuer](v); ok {
+		value, err := valuer.Value()
+		if err != nil || value == nil {
+			return "", err
+		}
+		if date, ok := value.(time.Time); ok {
+			return date.Format(format), nil
+		}
+		return fmt.Sprint(value), nil
+	}
+
+	if v.Kind() == reflect.Pointer {
+		v = v.Elem()
+	}
+
+	if v.Type() == timeType {
+		return v.Interface().(time.Time).Format(format), nil
+	}
+
+	if m, ok := asInterface[encoding.TextMarshaler](v); ok {
+		b, err := m.MarshalText()
+		return string(b), err
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Bool:
+		return strconv.FormatBool(v.Bool()), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(v.Uint(), 10), nil
+	case reflect.Float32:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 32), nil
+	case reflect.Float64:
+		return strconv.FormatFloat(v.Float(), 'f', -1, 64), nil
+	case reflect.Slice:
+		parts := make([]string, v.Len())
+		for i := range parts {
+			str, err := formatValue(v.Index(i), format, sep)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = str
+		}
+		return strings.Join(parts, sep), nil
+	}
+
+	return "", fmt.Errorf("unsupported field type: %v", v.Type())
+}
+
+// asInterface v or a pointer to v as I, whichever implements it
+func asInterface[I any](v reflect.Value) (I, bool) {
+	if i, ok := v.Interface().(I); ok {
+		return i, true
+	}
+	if v.CanAddr() {
+		if i, ok := v.Addr().Interface().(I); ok {
+			return i, true
+		}
+	}
+
+	var zero I
+	return zero, false
+}
@@ -0,0 +1,174 @@
+package csv
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"net/netip"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sjhitchner/toolbox/pkg/streaming"
+	. "gopkg.in/check.v1"
+)
+
+type FieldsSuite struct{}
+
+var _ = Suite(&FieldsSuite{})
+
+// cents parses and formats dollar amounts like $12.34
+type cents int64
+
+func (t *cents) UnmarshalCSV(str string) error {
+	whole, frac, _ := strings.Cut(strings.TrimPrefix(str, "$"), ".")
+
+	n, err := strconv.ParseInt(whole+frac, 10, 64)
+	if err != nil {
+		return err
+	}
+	*t = cents(n)
+	return nil
+}
+
+func (t cents) MarshalCSV() (string, error) {
+	return fmt.Sprintf("$%d.%02d", t/100, t%100), nil
+}
+
+type Audit struct {
+	CreatedBy string `csv:"created_by"`
+}
+
+type fieldsRow struct {
+	Audit
+
+	Name    *string         `csv:"name"`
+	Count   *int            `csv:"count"`
+	Score   sql.NullFloat64 `csv:"score"`
+	Seen    sql.NullTime    `csv:"seen,format=2006-01-02"`
+	Addr    netip.Addr      `csv:"addr"`
+	Price   cents           `csv:"price"`
+	Tags    []string        `csv:"tags,sep=|"`
+	Ids     []int           `csv:"ids"`
+	Date    time.Time       `csv:"date,format=02/01/2006"`
+	Ignored string          `csv:"-"`
+}
+
+const fieldsCSV = `created_by,name,count,score,seen,addr,price,tags,ids,date
+bob,foo,3,1.5,2021-05-06,10.0.0.1,$12.34,a|b,1;2;3,25/12/2021
+alice,,,,,::1,$0.05,,,01/01/2022
+`
+
+func (s *FieldsSuite) Test_ParseTag(c *C) {
+	name, opts := parseTag("date,format=2006-01-02,sep=|")
+	c.Assert(name, Equals, "date")
+	c.Assert(opts["format"], Equals, "2006-01-02")
+	c.Assert(opts["sep"], Equals, "|")
+
+	name, opts = parseTag("day,format='Jan 2, 2006',sep=',',optional")
+	c.Assert(name, Equals, "day")
+	c.Assert(opts["format"], Equals, "Jan 2, 2006")
+	c.Assert(opts["sep"], Equals, ",")
+	_, optional := opts["optional"]
+	c.Assert(optional, Equals, true)
+
+	name, opts = parseTag("it's,optional,format=x")
+	c.Assert(name, Equals, "it's")
+	c.Assert(opts, DeepEquals, map[string]string{"optional": "", "format": "x"})
+}
+
+func (s *FieldsSuite) Test_Reader_QuotedOptions(c *C) {
+	type quotedRow struct {
+		Day  time.Time `csv:"day,format='Jan 2, 2006'"`
+		Pair []int     `csv:"pair,sep=','"`
+	}
+
+	reader := NewReader[quotedRow]()
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader("day,pair\n\"Mar 4, 2021\",\"1,2\"\n"))
+	c.Assert(err, IsNil)
+	assertNoError(c, errCh)
+
+	row := <-outCh
+	c.Assert(row.Day.Equal(time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC)), Equals, true)
+	c.Assert(row.Pair, DeepEquals, []int{1, 2})
+}
+
+func (s *FieldsSuite) Test_Reader_EmptyTime(c *C) {
+	type timeRow struct {
+		Name     string    `csv:"name"`
+		Date     time.Time `csv:"date"`
+		Optional time.Time `csv:"optional,optional"`
+	}
+
+	reader := NewReader[timeRow]()
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.StreamFile("times.csv", strings.NewReader("name,date,optional\na,,\nb,2021-01-02T00:00:00Z,\n"))
+	c.Assert(err, IsNil)
+
+	rows, errs := drain(outCh, errCh)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `times.csv:2: column "date" field Date value "": invalid string date .*`)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(rows[0].Name, Equals, "b")
+	c.Assert(rows[0].Optional.IsZero(), Equals, true)
+}
+
+func (s *FieldsSuite) Test_Reader(c *C) {
+	reader := NewReader[fieldsRow]()
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader(fieldsCSV))
+	c.Assert(err, IsNil)
+	assertNoError(c, errCh)
+
+	row := <-outCh
+	c.Assert(row.CreatedBy, Equals, "bob")
+	c.Assert(*row.Name, Equals, "foo")
+	c.Assert(*row.Count, Equals, 3)
+	c.Assert(row.Score, Equals, sql.NullFloat64{Float64: 1.5, Valid: true})
+	c.Assert(row.Seen.Valid, Equals, true)
+	c.Assert(row.Seen.Time.Equal(time.Date(2021, 5, 6, 0, 0, 0, 0, time.UTC)), Equals, true)
+	c.Assert(row.Addr.String(), Equals, "10.0.0.1")
+	c.Assert(row.Price, Equals, cents(1234))
+	c.Assert(row.Tags, DeepEquals, []string{"a", "b"})
+	c.Assert(row.Ids, DeepEquals, []int{1, 2, 3})
+	c.Assert(row.Date.Equal(time.Date(2021, 12, 25, 0, 0, 0, 0, time.UTC)), Equals, true)
+
+	row = <-outCh
+	c.Assert(row.Name, IsNil)
+	c.Assert(row.Count, IsNil)
+	c.Assert(row.Score.Valid, Equals, false)
+	c.Assert(row.Seen.Valid, Equals, false)
+	c.Assert(row.Price, Equals, cents(5))
+	c.Assert(row.Tags, IsNil)
+}
+
+func (s *FieldsSuite) Test_Reader_Unsupported(c *C) {
+	type badRow struct {
+		Values map[string]string `csv:"0"`
+	}
+
+	outCh, errCh, err := NewReader[badRow]().Stream(strings.NewReader("x\n"))
+	c.Assert(err, IsNil)
+
+	c.Assert(<-errCh, ErrorMatches, `1: column "1" field Values value "x": unsupported field type: map.*`)
+	for _ = range outCh {
+		c.Fail()
+	}
+}
+
+func (s *FieldsSuite) Test_RoundTrip(c *C) {
+	reader := NewReader[fieldsRow]()
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader(fieldsCSV))
+	c.Assert(err, IsNil)
+	assertNoError(c, errCh)
+
+	buf := &bytes.Buffer{}
+	c.Assert(NewWriter[fieldsRow](buf).WriteAll(streaming.Gather(outCh)), IsNil)
+	c.Assert(buf.String(), Equals, fieldsCSV)
+}
@@ -0,0 +1,98 @@
+package csv
+
+import (
+	"fmt"
+	"strings"
+)
+
+// HeaderError header of a file does not match the struct being read.
+// Missing lists required columns that are not in the header, Unknown lists
+// header columns no field reads.
+type HeaderError struct {
+	File    string
+	Missing []string
+	Unknown []string
+}
+
+func (t *HeaderError) Error() string {
+	var problems []string
+	if len(t.Missing) > 0 {
+		problems = append(problems, fmt.Sprintf("missing columns %s", strings.Join(t.Missing, ", ")))
+	}
+	if len(t.Unknown) > 0 {
+		problems = append(problems, fmt.Sprintf("unknown columns %s", strings.Join(t.Unknown, ", ")))
+	}
+
+	msg := "invalid header: " + strings.Join(problems, "; ")
+	if t.File != "" {
+		msg = t.File + ": " + msg
+	}
+	return msg
+}
+
+// matchHeader the column of every spec in header, -1 if it is not present,
+// and the header columns that no spec matched. A spec matches on its name or
+// any of its aliases, ignoring case if ignoreCase is set.
+func matchHeader(header []string, specs []fieldSpec, ignoreCase bool) ([]int, []string) {
+	normalize := func(s string) string {
+		s = strings.TrimSpace(strings.TrimPrefix(s, "\ufeff"))
+		if ignoreCase {
+			return strings.ToLower(s)
+		}
+		return s
+	}
+
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		name = normalize(name)
+		if _, found := index[name]; !found {
+			index[name] = i
+		}
+	}
+
+	used := make([]bool, len(header))
+	columns := make([]int, len(specs))
+	for i, spec := range specs {
+		columns[i] = -1
+
+		for _, name := range append([]string{spec.name}, spec.aliases...) {
+			if col, found := index[normalize(name)]; found {
+				columns[i] = col
+				used[col] = true
+				break
+			}
+		}
+	}
+
+	var unknown []string
+	for i, name := range header {
+		if !used[i] {
+			unknown = append(unknown, name)
+		}
+	}
+
+	return columns, unknown
+}
+
+// validateHeader check the matched columns against the strict settings,
+// returns nil if the header is acceptable
+func validateHeader(specs []fieldSpec, columns []int, unknown []string, strict, disallowUnknown bool) *HeaderError {
+	herr := &HeaderError{}
+
+	if strict {
+		for i, spec := range specs {
+			if columns[i] < 0 && !spec.optional {
+				herr.Missing = append(herr.Missing, spec.name)
+			}
+		}
+	}
+
+	if disallowUnknown {
+		herr.Unknown = unknown
+	}
+
+	if len(herr.Missing) == 0 && len(herr.Unknown) == 0 {
+		return nil
+	}
+	return herr
+}
@@ -0,0 +1,92 @@
+package csv
+
+import (
+	"errors"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type HeaderSuite struct{}
+
+var _ = Suite(&HeaderSuite{})
+
+type headerRow struct {
+	ID     int    `csv:"id"`
+	Amount int    `csv:"amount,alias=amt|total"`
+	Note   string `csv:"note,optional"`
+}
+
+func readHeaderRows(c *C, reader *Reader[headerRow], data string) ([]headerRow, []error) {
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader(data))
+	c.Assert(err, IsNil)
+
+	return drain(outCh, errCh)
+}
+
+func (s *HeaderSuite) Test_Alias(c *C) {
+	rows, errs := readHeaderRows(c, NewReader[headerRow](), "id,total\n1,10\n2,20\n")
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, DeepEquals, []headerRow{{ID: 1, Amount: 10}, {ID: 2, Amount: 20}})
+}
+
+func (s *HeaderSuite) Test_IgnoreCase(c *C) {
+	reader := NewReader[headerRow]()
+	rows, errs := readHeaderRows(c, reader, "ID,Amt\n1,10\n")
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, DeepEquals, []headerRow{{}})
+
+	reader.IgnoreCase = true
+	rows, errs = readHeaderRows(c, reader, "ID,Amt\n1,10\n")
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, DeepEquals, []headerRow{{ID: 1, Amount: 10}})
+}
+
+func (s *HeaderSuite) Test_Strict(c *C) {
+	reader := NewReader[headerRow]()
+	reader.Strict = true
+
+	rows, errs := readHeaderRows(c, reader, "id,amount,extra\n1,10,x\n")
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, HasLen, 1)
+
+	rows, errs = readHeaderRows(c, reader, "id,note\n1,x\n")
+	c.Assert(rows, HasLen, 0)
+	c.Assert(errs, HasLen, 1)
+
+	var herr *HeaderError
+	c.Assert(errors.As(errs[0], &herr), Equals, true)
+	c.Assert(herr.Missing, DeepEquals, []string{"amount"})
+	c.Assert(herr.Unknown, HasLen, 0)
+	c.Assert(herr.Error(), Equals, "invalid header: missing columns amount")
+}
+
+func (s *HeaderSuite) Test_DisallowUnknown(c *C) {
+	reader := NewReader[headerRow]()
+	reader.Strict = true
+	reader.DisallowUnknown = true
+
+	rows, errs := readHeaderRows(c, reader, "id,extra,other\n1,x,y\n")
+	c.Assert(rows, HasLen, 0)
+	c.Assert(errs, HasLen, 1)
+
+	var herr *HeaderError
+	c.Assert(errors.As(errs[0], &herr), Equals, true)
+	c.Assert(herr.Missing, DeepEquals, []string{"amount"})
+	c.Assert(herr.Unknown, DeepEquals, []string{"extra", "other"})
+	c.Assert(herr.Error(), Equals, "invalid header: missing columns amount; unknown columns extra, other")
+}
+
+func (s *HeaderSuite) Test_MatchHeader(c *C) {
+	specs := []fieldSpec{
+		{name: "a"},
+		{name: "b", aliases: []string{"bee"}},
+		{name: "c"},
+	}
+
+	columns, unknown := matchHeader([]string{"BEE", " a ", "d"}, specs, true)
+	c.Assert(columns, DeepEquals, []int{1, 0, -1})
+	c.Assert(unknown, DeepEquals, []string{"d"})
+}
@@ -0,0 +1,381 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+
+	"github.com/sjhitchner/toolbox/pkg/streaming"
+)
+
+const (
+	// DefaultChunkSize bytes of input parsed by each StreamParallel task
+	DefaultChunkSize = 4 << 20
+)
+
+// chunk a run of whole records, line is the line the first record starts on
+type chunk struct {
+	seq  int
+	line int
+	data []byte
+}
+
+// parsedChunk the rows and errors of a chunk in the order they were read
+type parsedChunk[T any] struct {
+	seq  int
+	rows []parsedRow[T]
+}
+
+type parsedRow[T any] struct {
+	obj  T
+	row  []string
+	line int
+	errs []*ParseError
+}
+
+// chunker splits input into chunks of about size bytes that end on a record
+// boundary. A newline ends a record unless it is inside a quoted field,
+// quoting is tracked by counting quotes as RFC 4180 escapes a quote by
+// doubling it.
+type chunker struct {
+	reader io.Reader
+	size   int
+	buf    []byte
+	eof    bool
+	seq    int
+	line   int
+
+	// scan state of buf
+	scanned  int
+	quoted   bool
+	boundary int
+}
+
+func newChunker(reader io.Reader, size int) *chunker {
+	return &chunker{
+		reader: reader,
+		size:   size,
+		line:   1,
+	}
+}
+
+// fill read up to size more bytes into buf
+func (t *chunker) fill() error {
+	if cap(t.buf)-len(t.buf) < t.size {
+		buf := make([]byte, len(t.buf), len(t.buf)+t.size)
+		copy(buf, t.buf)
+		t.buf = buf
+	}
+
+	n, err := io.ReadFull(t.reader, t.buf[len(t.buf):len(t.buf)+t.size])
+	t.buf = t.buf[:len(t.buf)+n]
+
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		t.eof = true
+		return nil
+	}
+	return err
+}
+
+// scan find the last record boundary in buf
+func (t *chunker) scan() {
+	for i := t.scanned; i < len(t.buf); i++ {
+		switch t.buf[i] {
+		case '"':
+			t.quoted = !t.quoted
+		case '\n':
+			if !t.quoted {
+				t.boundary = i + 1
+			}
+		}
+	}
+	t.scanned = len(t.buf)
+}
+
+// next chunk, io.EOF once the input is exhausted. A record larger than size
+// is read whole into one chunk.
+func (t *chunker) next() (*chunk, error) {
+	for {
+		if !t.eof {
+			if err := t.fill(); err != nil {
+				return nil, err
+			}
+		}
+		t.scan()
+
+		end := t.boundary
+		if t.eof {
+			end = len(t.buf)
+		}
+
+		if end == 0 {
+			if t.eof {
+				return nil, io.EOF
+			}
+			continue
+		}
+
+		// The chunk keeps its bytes, the remainder moves to a new buffer
+		data := t.buf[:end]
+		rest := make([]byte, len(t.buf)-end, len(t.buf)-end+t.size)
+		copy(rest, t.buf[end:])
+
+		t.buf = rest
+		t.scanned = 0
+		t.quoted = false
+		t.boundary = 0
+
+		c := &chunk{
+			seq:  t.seq,
+			line: t.line,
+			data: data,
+		}
+		t.seq++
+		t.line += bytes.Count(data, []byte{'\n'})
+		return c, nil
+	}
+}
+
+// StreamParallel like StreamFile for large inputs. The input is split into
+// chunks of ChunkSize bytes at record boundaries which Workers goroutines
+// parse at once. Rows keep their order within a chunk, chunks are emitted
+// as they finish unless Ordered is set. Fields must be quoted as RFC 4180
+// describes for the chunk boundaries to be found, a quote inside an
+// unquoted field or a comment misplaces them.
+func (t *Reader[T]) StreamParallel(filename string, reader io.Reader) (<-chan T, <-chan error, error) {
+	outCh := make(chan T)
+	errCh := make(chan error, 1)
+
+	typ, err := structType[T]()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	workers := t.Workers
+	if workers < 1 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	size := t.ChunkSize
+	if size < 1 {
+		size = DefaultChunkSize
+	}
+
+	go func() {
+		defer close(outCh)
+		defer close(errCh)
+
+		input, err := skipLines(reader, t.SkipRows)
+		if err != nil {
+			errCh <- readError(filename, 1, err)
+			return
+		}
+
+		chunker := newChunker(input, size)
+		chunker.line += t.SkipRows
+
+		first, err := chunker.next()
+		if err != nil {
+			if t.HasHeader {
+				errCh <- fmt.Errorf("error reading header: %w", err)
+			} else if err != io.EOF {
+				errCh <- readError(filename, 1, err)
+			}
+			return
+		}
+
+		// Every chunk expects as many fields as the first record of the file
+		records := t.newCSVReader(bytes.NewReader(first.data))
+		record, err := records.Read()
+		fields := len(record)
+
+		var header []string
+		if t.HasHeader {
+			if err != nil {
+				errCh <- fmt.Errorf("error reading header: %w", err)
+				return
+			}
+
+			header = record
+			offset := records.InputOffset()
+			first.line += bytes.Count(first.data[:offset], []byte{'\n'})
+			first.data = first.data[offset:]
+		}
+
+		decoder, herr := t.newRowDecoder(typ, header)
+		if herr != nil {
+			herr.File = filename
+			errCh <- herr
+			return
+		}
+
+		// tokens bounds the chunks in flight, so ordering cannot buffer
+		// more than 2*workers chunks waiting on a slow one
+		tokens := make(chan struct{}, 2*workers)
+		done := make(chan struct{})
+		chunks := make(chan *chunk)
+		results := make(chan parsedChunk[T])
+
+		var chunkErr error
+		var chunkErrLine int
+
+		go func() {
+			defer close(chunks)
+
+			for c := first; ; {
+				select {
+				case tokens <- struct{}{}:
+				case <-done:
+					return
+				}
+
+				select {
+				case chunks <- c:
+				case <-done:
+					return
+				}
+
+				var err error
+				c, err = chunker.next()
+				if err == io.EOF {
+					return
+				}
+				if err != nil {
+					chunkErr, chunkErrLine = err, chunker.line
+					return
+				}
+			}
+		}()
+
+		var wg sync.WaitGroup
+		for i := 0; i < workers; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+
+				for c := range chunks {
+					parsed := decoder.parseChunk(c, fields)
+					select {
+					case results <- parsed:
+					case <-done:
+						return
+					}
+				}
+			}()
+		}
+
+		go func() {
+			wg.Wait()
+			close(results)
+		}()
+
+		report := t.reporter(filename, errCh)
+		unique := uniqueValues{}
+
+		// emit returns true if reading should stop, unique rules are
+		// checked here as they depend on the rows emitted before
+		emit := func(parsed parsedChunk[T]) bool {
+			defer func() { <-tokens }()
+
+			for _, row := range parsed.rows {
+				errs := row.errs
+				if len(errs) == 0 && row.row != nil {
+					errs = decoder.checkUnique(row.obj, row.row, row.line, unique)
+				}
+
+				if len(errs) > 0 {
+					for _, err := range errs {
+						if report(err) {
+							return true
+						}
+					}
+					continue
+				}
+				outCh <- row.obj
+			}
+			return false
+		}
+
+		pending := make(map[int]parsedChunk[T])
+		next := 0
+		stopped := false
+
+		for parsed := range results {
+			if !t.Ordered {
+				if stopped = emit(parsed); stopped {
+					break
+				}
+				continue
+			}
+
+			pending[parsed.seq] = parsed
+			for !stopped {
+				ready, found := pending[next]
+				if !found {
+					break
+				}
+				delete(pending, next)
+				next++
+				stopped = emit(ready)
+			}
+			if stopped {
+				break
+			}
+		}
+
+		if stopped {
+			close(done)
+			streaming.Consume(results)
+			return
+		}
+
+		if chunkErr != nil {
+			errCh <- readError(filename, chunkErrLine, chunkErr)
+		}
+	}()
+
+	return outCh, errCh, nil
+}
+
+// parseChunk decode every record of c, fields is the number of fields each
+// record must have
+func (t *rowDecoder[T]) parseChunk(c *chunk, fields int) parsedChunk[T] {
+	parsed := parsedChunk[T]{seq: c.seq}
+
+	reader := t.reader.newCSVReader(bytes.NewReader(c.data))
+	reader.FieldsPerRecord = fields
+
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			parseErr := readError("", line, err)
+			parseErr.Line += c.line - 1
+			parsed.rows = append(parsed.rows, parsedRow[T]{errs: []*ParseError{parseErr}})
+
+			var csvErr *csv.ParseError
+			if !errors.As(err, &csvErr) {
+				break
+			}
+			continue
+		}
+		line, _ = reader.FieldPos(0)
+
+		obj, errs := t.decode(row, line+c.line-1)
+		parsed.rows = append(parsed.rows, parsedRow[T]{
+			obj:  obj,
+			row:  row,
+			line: line + c.line - 1,
+			errs: errs,
+		})
+	}
+
+	return parsed
+}
@@ -0,0 +1,165 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type ParallelSuite struct{}
+
+var _ = Suite(&ParallelSuite{})
+
+type parallelRow struct {
+	ID   int    `csv:"id"`
+	Name string `csv:"name"`
+	Note string `csv:"note"`
+}
+
+// parallelCSV n rows with quoted fields holding commas, quotes and
+// newlines, every tenth row has an invalid id
+func parallelCSV(n int) string {
+	buf := &strings.Builder{}
+	buf.WriteString("id,name,note\n")
+	for i := 0; i < n; i++ {
+		id := fmt.Sprint(i)
+		if i%10 == 9 {
+			id = "x" + id
+		}
+		fmt.Fprintf(buf, "%s,name%d,\"line one, \"\"quoted\"\"\nline two %d\"\n", id, i, i)
+	}
+	return buf.String()
+}
+
+func (s *ParallelSuite) stream(c *C, reader *Reader[parallelRow], data string, parallel bool) ([]parallelRow, []error) {
+	reader.HasHeader = true
+
+	stream := reader.StreamFile
+	if parallel {
+		stream = reader.StreamParallel
+	}
+
+	outCh, errCh, err := stream("big.csv", strings.NewReader(data))
+	c.Assert(err, IsNil)
+	return drain(outCh, errCh)
+}
+
+func errorStrings(errs []error) []string {
+	strs := make([]string, len(errs))
+	for i, err := range errs {
+		strs[i] = err.Error()
+	}
+	return strs
+}
+
+func (s *ParallelSuite) Test_Chunker(c *C) {
+	data := "a,b\n\"c\nd\",e\n" + strings.Repeat("x", 20) + "\nf,g"
+	chunker := newChunker(strings.NewReader(data), 5)
+
+	var chunks []string
+	var lines []int
+	for {
+		chunk, err := chunker.next()
+		if err == io.EOF {
+			break
+		}
+		c.Assert(err, IsNil)
+		chunks = append(chunks, string(chunk.data))
+		lines = append(lines, chunk.line)
+	}
+
+	c.Assert(strings.Join(chunks, ""), Equals, data)
+	c.Assert(chunks, DeepEquals, []string{"a,b\n", "\"c\nd\",e\n", strings.Repeat("x", 20) + "\n", "f,g"})
+	c.Assert(lines, DeepEquals, []int{1, 2, 4, 5})
+}
+
+func (s *ParallelSuite) Test_Ordered(c *C) {
+	data := parallelCSV(500)
+
+	expectedRows, expectedErrs := s.stream(c, NewReader[parallelRow](), data, false)
+	c.Assert(expectedRows, HasLen, 450)
+	c.Assert(expectedErrs, HasLen, 50)
+
+	reader := NewReader[parallelRow]()
+	reader.Workers = 4
+	reader.ChunkSize = 256
+	reader.Ordered = true
+
+	rows, errs := s.stream(c, reader, data, true)
+	c.Assert(rows, DeepEquals, expectedRows)
+	c.Assert(errorStrings(errs), DeepEquals, errorStrings(expectedErrs))
+}
+
+func (s *ParallelSuite) Test_Unordered(c *C) {
+	data := parallelCSV(500)
+	expectedRows, expectedErrs := s.stream(c, NewReader[parallelRow](), data, false)
+
+	reader := NewReader[parallelRow]()
+	reader.Workers = 4
+	reader.ChunkSize = 100
+
+	rows, errs := s.stream(c, reader, data, true)
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i].ID < rows[j].ID
+	})
+	c.Assert(rows, DeepEquals, expectedRows)
+
+	actual, expected := errorStrings(errs), errorStrings(expectedErrs)
+	sort.Strings(actual)
+	sort.Strings(expected)
+	c.Assert(actual, DeepEquals, expected)
+}
+
+func (s *ParallelSuite) Test_FailFast(c *C) {
+	reader := NewReader[parallelRow]()
+	reader.ErrorPolicy = FailFast
+	reader.Workers = 4
+	reader.ChunkSize = 64
+	reader.Ordered = true
+
+	rows, errs := s.stream(c, reader, parallelCSV(500), true)
+	c.Assert(rows, HasLen, 9)
+	c.Assert(errs, HasLen, 1)
+
+	var parseErr *ParseError
+	c.Assert(errors.As(errs[0], &parseErr), Equals, true)
+	c.Assert(parseErr.Line, Equals, 20)
+	c.Assert(parseErr.Column, Equals, "id")
+}
+
+func (s *ParallelSuite) Test_FieldCount(c *C) {
+	reader := NewReader[parallelRow]()
+	reader.Workers = 2
+	reader.ChunkSize = 8
+	reader.Ordered = true
+
+	rows, errs := s.stream(c, reader, "id,name,note\n1,a,b\n2,c\n3,d,e\n", true)
+	c.Assert(rows, DeepEquals, []parallelRow{{1, "a", "b"}, {3, "d", "e"}})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, "big.csv:3: wrong number of fields")
+}
+
+func (s *ParallelSuite) Test_Empty(c *C) {
+	reader := NewReader[parallelRow]()
+
+	rows, errs := s.stream(c, reader, "id,name,note\n", true)
+	c.Assert(rows, HasLen, 0)
+	c.Assert(errs, HasLen, 0)
+
+	rows, errs = s.stream(c, reader, "", true)
+	c.Assert(rows, HasLen, 0)
+	c.Assert(errs, HasLen, 1)
+}
+
+func (s *ParallelSuite) Test_CachedFieldSpecs(c *C) {
+	typ, err := structType[parallelRow]()
+	c.Assert(err, IsNil)
+
+	specs := cachedFieldSpecs(typ)
+	c.Assert(specs, DeepEquals, fieldSpecs(typ))
+	c.Assert(&cachedFieldSpecs(typ)[0], Equals, &specs[0])
+}
@@ -4,41 +4,54 @@ Package to interact with CSV files, finding, reading, processing.  Uses pipeline
 package csv
 
 import (
-	"bytes"
+	"context"
+	"encoding/csv"
+	"errors"
 	"fmt"
+	"io"
 	"net/url"
 	"strings"
 	"sync"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
-	"github.com/aws/aws-sdk-go/service/s3"
-	"github.com/aws/aws-sdk-go/service/s3/s3manager"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
 	zl "github.com/rs/zerolog"
-	"github.com/sjhitchner/toolbox/pkg/streaming"
+)
+
+const (
+	// DefaultS3Concurrency objects downloaded at once by an S3Reader
+	DefaultS3Concurrency = 4
 )
 
 // PipelineFunc process a row to add information based on filename
 type PipelineFunc func(filename string, row []string) []string
 
+// S3Client the S3 calls made by S3Reader, *s3.Client implements it and tests
+// may substitute a mock
+type S3Client interface {
+	s3.ListObjectsV2APIClient
+	GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error)
+}
+
+// S3Reader streams the rows of every CSV object under a prefix. Objects are
+// read straight from the GetObject body, decompressed if needed, with at
+// most concurrency objects open at once.
 type S3Reader struct {
-	sess          *session.Session
-	s3            *s3.S3
-	downloader    *s3manager.Downloader
+	client        S3Client
 	terminator    rune
 	fileFilter    FilterFunc
 	pipelineFuncs []PipelineFunc
+	concurrency   int
 	logger        zl.Logger
 }
 
-func NewS3Reader(sess *session.Session, logger *zl.Logger) *S3Reader {
+func NewS3Reader(client S3Client, logger *zl.Logger) *S3Reader {
 	return &S3Reader{
-		sess:       sess,
-		downloader: s3manager.NewDownloader(sess),
-		s3:         s3.New(sess),
-		terminator: ',',
-		fileFilter: IsCSV,
-		logger:     logger.With().Logger(),
+		client:      client,
+		terminator:  ',',
+		fileFilter:  IsCSV,
+		concurrency: DefaultS3Concurrency,
+		logger:      logger.With().Logger(),
 	}
 }
 
@@ -57,114 +70,110 @@ func (t *S3Reader) WithPipeline(fns ...PipelineFunc) *S3Reader {
 	return t
 }
 
-// listS3
-// List files at S3 path
-func (t *S3Reader) List(bucket, prefix string) (<-chan string, error) {
+// WithConcurrency maximum number of objects downloaded at once
+func (t *S3Reader) WithConcurrency(n int) *S3Reader {
+	if n < 1 {
+		n = 1
+	}
+	t.concurrency = n
+	return t
+}
+
+// List keys under prefix that pass the filter, every page of the listing is
+// read. A failed page stops the listing and is reported on the error channel.
+func (t *S3Reader) List(ctx context.Context, bucket, prefix string) (<-chan string, <-chan error) {
+	out := make(chan string)
+	errCh := make(chan error, 1)
 
 	input := &s3.ListObjectsV2Input{
 		Bucket: aws.String(bucket),
 		Prefix: aws.String(prefix),
 	}
 
-	fmt.Println(input)
-
-	result, err := t.s3.ListObjectsV2(input)
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Println(result)
-
-	out := make(chan string)
 	go func() {
 		defer close(out)
-
-		for _, obj := range result.Contents {
-			file := aws.StringValue(obj.Key)
-
-			t.logger.Info().
-				Interface("result", result).
-				Str("file", file).
-				Msg("List S3")
-
-			if !t.fileFilter(file) {
-				continue
+		defer close(errCh)
+
+		paginator := s3.NewListObjectsV2Paginator(t.client, input)
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				sendError(ctx, errCh, fmt.Errorf("listing s3://%s/%s: %w", bucket, prefix, err))
+				return
 			}
 
-			t.logger.Info().
-				Str("file", file).
-				Msg("adding file to queue")
-
-			out <- file
+			for _, obj := range page.Contents {
+				file := aws.ToString(obj.Key)
+				if !t.fileFilter(TrimCompression(file)) {
+					continue
+				}
+
+				t.logger.Debug().
+					Str("bucket", bucket).
+					Str("file", file).
+					Msg("adding file to queue")
+
+				select {
+				case out <- file:
+				case <-ctx.Done():
+					return
+				}
+			}
 		}
 	}()
 
-	return out, nil
+	return out, errCh
 }
 
-// Stream - streams CSV from S3 row by row
-func (t *S3Reader) Stream(bucket, path string, header bool) (<-chan []string, error) {
-	logger := t.logger.With().
+// Stream - streams CSV from S3 row by row. Rows are passed through the
+// pipeline functions in order. Errors reading a row are reported and the row
+// skipped, an object that cannot be read is reported and skipped. Cancelling
+// ctx stops the stream, errors not yet received are then discarded.
+func (t *S3Reader) Stream(ctx context.Context, bucket, path string, header bool) (<-chan []string, <-chan error) {
+	t.logger.Info().
 		Str("bucket", bucket).
 		Str("path", path).
-		Logger()
-
-	logger.Info().Msg("Reading S3 Path")
-
-	files, err := t.List(bucket, path)
-	if err != nil {
-		return nil, err
-	}
-
-	rows, err := t.stream(bucket, header, files)
-	if err != nil {
-		return nil, err
-	}
-
-	return rows, nil
-}
-
-func (t *S3Reader) stream(bucket string, header bool, files <-chan string) (<-chan []string, error) {
+		Msg("Reading S3 Path")
 
 	out := make(chan []string)
-	var wg sync.WaitGroup
+	errCh := make(chan error)
 
-	downloadFn := func(filename string) {
-		defer wg.Done()
-		rows, err := t.download(bucket, filename, header)
-		if err != nil {
-			t.logger.Error().
-				Err(err).
-				Str("bucket", bucket).
-				Str("key", filename).
-				Msg("failed reading S3 file")
-			return
-		}
+	files, listErrCh := t.List(ctx, bucket, path)
+	listDone := copyErrors(ctx, errCh, listErrCh)
 
-		for row := range rows {
-			for _, fn := range t.pipelineFuncs {
-				row = fn(filename, row)
+	var wg sync.WaitGroup
+	for i := 0; i < t.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for file := range files {
+				if err := t.download(ctx, bucket, file, header, out, errCh); err != nil {
+					t.logger.Error().
+						Err(err).
+						Str("bucket", bucket).
+						Str("key", file).
+						Msg("failed reading S3 file")
+					sendError(ctx, errCh, err)
+				}
 			}
-			out <- row
-		}
+		}()
 	}
 
 	go func() {
+		defer close(errCh)
 		defer close(out)
 
-		for file := range files {
-			wg.Add(1)
-			go downloadFn(file)
-		}
-
 		wg.Wait()
+		<-listDone
 	}()
 
-	return out, nil
+	return out, errCh
 }
 
-func (t *S3Reader) download(bucket, file string, header bool) (<-chan []string, error) {
-
+// download stream the rows of one object to out, returns an error if the
+// object cannot be read
+func (t *S3Reader) download(ctx context.Context, bucket, file string, header bool, out chan<- []string, errCh chan<- error) error {
 	input := &s3.GetObjectInput{
 		Bucket: aws.String(bucket),
 		Key:    aws.String(file),
@@ -174,81 +183,63 @@ func (t *S3Reader) download(bucket, file string, header bool) (<-chan []string,
 		Str("file", file).
 		Msg("downloading s3 file")
 
-	// buf := make([]byte, 100000000)
-	writer := aws.NewWriteAtBuffer([]byte{})
-
-	_, err := t.downloader.Download(writer, input)
-	if err != nil {
-		return nil, err
-	}
-
-	reader := NewReader(bytes.NewReader(writer.Bytes()))
-	reader.Comma = t.terminator
+	source := fmt.Sprintf("s3://%s/%s", bucket, file)
 
-	outCh, errCh, err := Stream[[]string](reader, header)
+	output, err := t.client.GetObject(ctx, input)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("%s: %w", source, err)
 	}
-	return outCh, streaming.Error(errCh)
-}
 
-func ParseS3Path(s3Path string) (string, string, error) {
-	u, err := url.Parse(s3Path)
+	body, err := Decompress(file, output.Body)
 	if err != nil {
-		return "", "", err
+		return fmt.Errorf("%s: %w", source, err)
 	}
+	defer body.Close()
 
-	return strings.TrimSpace(u.Host),
-		strings.TrimSpace(u.Path[1:]), nil
-}
-
-/*
-
-// Create a file to write the S3 Object contents to.
-f, err := os.Create(filename)
-if err != nil {
-    return fmt.Errorf("failed to create file %q, %v", filename, err)
-}
+	reader := csv.NewReader(body)
+	reader.Comma = t.terminator
 
-// Write the contents of S3 Object to the file
-n, err := downloader.Download(f, &s3.GetObjectInput{
-    Bucket: aws.String(myBucket),
-    Key:    aws.String(myString),
-})
-if err != nil {
-    return fmt.Errorf("failed to download file, %v", err)
-}
-fmt.Printf("file downloaded, %d bytes\n", n)
-}
+	skipHeader := header
+	for line := 1; ; line++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			var csvErr *csv.ParseError
+			if !errors.As(err, &csvErr) {
+				return fmt.Errorf("%s: %w", source, err)
+			}
+			if !sendError(ctx, errCh, readError(source, line, err)) {
+				return nil
+			}
+			continue
+		}
+		line, _ = reader.FieldPos(0)
 
-// readRows
-// Based on provided csvPath the correct streaming method will be used
-// - file
-// - directory
-// - s3 path (s3://)
-func readRows(sess *session.Session, header bool, listID, csvPath string, logger *zl.Logger) (<-chan []string, error) {
+		if skipHeader {
+			skipHeader = false
+			continue
+		}
 
-	log := logger.With().
-		Str("path", csvPath).
-		Logger()
+		for _, fn := range t.pipelineFuncs {
+			row = fn(file, row)
+		}
 
-	if strings.HasPrefix(csvPath, "s3://") {
-		return readCSVS3(sess, csvPath, header, &log)
+		select {
+		case out <- row:
+		case <-ctx.Done():
+			return nil
+		}
 	}
+}
 
-	fileInfo, err := os.Stat(csvPath)
+func ParseS3Path(s3Path string) (string, string, error) {
+	u, err := url.Parse(s3Path)
 	if err != nil {
-		return nil, err
-	}
-
-	if fileInfo.IsDir() {
-		return walkCSVs(csvPath, header, &log)
-	}
-
-	if listID == "" {
-		return nil, fmt.Errorf("list-id must be specified")
+		return "", "", err
 	}
 
-	return readCSVFile(listID, csvPath, header, &log)
+	return strings.TrimSpace(u.Host),
+		strings.TrimSpace(strings.TrimPrefix(u.Path, "/")), nil
 }
-*/
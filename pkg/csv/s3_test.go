@@ -1,56 +1,197 @@
 package csv
 
-/*
 import (
-	"fmt"
-	"os"
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
 	zl "github.com/rs/zerolog"
 
 	. "gopkg.in/check.v1"
 )
 
-const (
-	Profile = "dev"
-	Region  = "us-east-1"
-	Bucket  = "stephen"
-	Prefix  = "data"
-)
+// mockS3 in memory S3Client, listings return pageSize keys per page
+type mockS3 struct {
+	objects  map[string][]byte
+	pageSize int
+
+	mu      sync.Mutex
+	pages   int
+	open    int
+	maxOpen int
+}
+
+func (t *mockS3) ListObjectsV2(ctx context.Context, params *s3.ListObjectsV2Input, optFns ...func(*s3.Options)) (*s3.ListObjectsV2Output, error) {
+	t.mu.Lock()
+	t.pages++
+	t.mu.Unlock()
+
+	var keys []string
+	for key := range t.objects {
+		if strings.HasPrefix(key, aws.ToString(params.Prefix)) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	start := 0
+	if params.ContinuationToken != nil {
+		start, _ = strconv.Atoi(*params.ContinuationToken)
+	}
+	end := min(start+t.pageSize, len(keys))
+
+	output := &s3.ListObjectsV2Output{
+		IsTruncated: aws.Bool(end < len(keys)),
+	}
+	for _, key := range keys[start:end] {
+		output.Contents = append(output.Contents, types.Object{Key: aws.String(key)})
+	}
+	if end < len(keys) {
+		output.NextContinuationToken = aws.String(strconv.Itoa(end))
+	}
+	return output, nil
+}
+
+func (t *mockS3) GetObject(ctx context.Context, params *s3.GetObjectInput, optFns ...func(*s3.Options)) (*s3.GetObjectOutput, error) {
+	data, found := t.objects[aws.ToString(params.Key)]
+	if !found {
+		return nil, errors.New("NoSuchKey")
+	}
+
+	t.mu.Lock()
+	t.open++
+	t.maxOpen = max(t.maxOpen, t.open)
+	t.mu.Unlock()
+
+	return &s3.GetObjectOutput{
+		Body: &mockBody{Reader: bytes.NewReader(data), s3: t},
+	}, nil
+}
+
+type mockBody struct {
+	io.Reader
+	s3 *mockS3
+}
+
+func (t *mockBody) Close() error {
+	t.s3.mu.Lock()
+	t.s3.open--
+	t.s3.mu.Unlock()
+	return nil
+}
 
 type S3Suite struct {
-	sess   *session.Session
+	client *mockS3
 	reader *S3Reader
 }
 
 var _ = Suite(&S3Suite{})
 
-func (s *S3Suite) SetUpSuite(c *C) {
-	s.sess = session.Must(session.NewSessionWithOptions(session.Options{
-		SharedConfigState: session.SharedConfigEnable,
-		Profile:           Profile,
-		Config: aws.Config{
-			Region: aws.String(Region),
-		},
-	}))
+func (s *S3Suite) SetUpTest(c *C) {
+	s.client = &mockS3{
+		objects:  make(map[string][]byte),
+		pageSize: 3,
+	}
 
-	logger := zl.New(zl.ConsoleWriter{Out: os.Stdout}).With().
-		Timestamp().
-		Logger()
+	for i := 0; i < 10; i++ {
+		s.client.objects["data/"+strconv.Itoa(i)+".csv"] = []byte("name,amount\nrow" + strconv.Itoa(i) + ",1\n")
+	}
+	s.client.objects["data/skip.json"] = []byte("{}")
+	s.client.objects["data/z.csv.gz"] = gzipData(c, "name,amount\nzipped,2\n")
+	s.client.objects["other/x.csv"] = []byte("name,amount\nother,3\n")
 
-	s.reader = NewS3Reader(s.sess, &logger)
+	logger := zl.Nop()
+	s.reader = NewS3Reader(s.client, &logger).WithConcurrency(2)
 }
 
 func (s *S3Suite) Test_List(c *C) {
+	files, errs := drain(s.reader.List(context.Background(), "bucket", "data/"))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(files, HasLen, 11)
+	c.Assert(files[10], Equals, "data/z.csv.gz")
+	c.Assert(s.client.pages, Equals, 4)
+}
+
+func (s *S3Suite) Test_Stream(c *C) {
+	s.reader.WithPipeline(func(filename string, row []string) []string {
+		return append(row, filename)
+	})
+
+	rows, errs := drain(s.reader.Stream(context.Background(), "bucket", "data/", true))
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, HasLen, 11)
+
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0] < rows[j][0]
+	})
+	c.Assert(rows[0], DeepEquals, []string{"row0", "1", "data/0.csv"})
+	c.Assert(rows[10], DeepEquals, []string{"zipped", "2", "data/z.csv.gz"})
+
+	c.Assert(s.client.open, Equals, 0)
+	c.Assert(s.client.maxOpen <= 2, Equals, true)
+}
+
+func (s *S3Suite) Test_Stream_Errors(c *C) {
+	s.client.objects = map[string][]byte{
+		"data/a.csv": []byte("a,1\nb,\"2\n"),
+		"data/b.csv": []byte("c,3\n"),
+	}
+
+	rows, errs := drain(s.reader.Stream(context.Background(), "bucket", "data/", false))
+	sort.Slice(rows, func(i, j int) bool {
+		return rows[i][0] < rows[j][0]
+	})
+	c.Assert(rows, DeepEquals, [][]string{{"a", "1"}, {"c", "3"}})
+	c.Assert(errs, HasLen, 1)
+
+	var parseErr *ParseError
+	c.Assert(errors.As(errs[0], &parseErr), Equals, true)
+	c.Assert(parseErr.File, Equals, "s3://bucket/data/a.csv")
+}
+
+func (s *S3Suite) Test_ParseS3Path(c *C) {
+	bucket, key, err := ParseS3Path("s3://bucket/data/a.csv")
+	c.Assert(err, IsNil)
+	c.Assert(bucket, Equals, "bucket")
+	c.Assert(key, Equals, "data/a.csv")
 
-	files, err := s.reader.List(Bucket, Prefix)
+	bucket, key, err = ParseS3Path("s3://bucket")
 	c.Assert(err, IsNil)
+	c.Assert(bucket, Equals, "bucket")
+	c.Assert(key, Equals, "")
+}
+
+func (s *S3Suite) Test_Stream_CancelUnread(c *C) {
+	// Every row is malformed, the errors are never read
+	bad := strings.Repeat("a\"b,1\n", 1000)
+	for i := 0; i < 4; i++ {
+		s.client.objects["bad/"+strconv.Itoa(i)+".csv"] = []byte(bad)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out, errCh := s.reader.Stream(ctx, "bucket", "bad/", false)
+	<-errCh
+	cancel()
 
-	fmt.Println("here")
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range out {
+		}
+	}()
 
-	for file := range files {
-		fmt.Println(file)
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatal("stream blocked on an unread error channel")
 	}
 }
-*/
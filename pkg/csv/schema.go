@@ -0,0 +1,331 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ColumnType the Go type guessed for a column
+type ColumnType int
+
+const (
+	StringColumn ColumnType = iota
+	IntColumn
+	FloatColumn
+	BoolColumn
+	TimeColumn
+)
+
+func (t ColumnType) String() string {
+	switch t {
+	case StringColumn:
+		return "string"
+	case IntColumn:
+		return "int64"
+	case FloatColumn:
+		return "float64"
+	case BoolColumn:
+		return "bool"
+	case TimeColumn:
+		return "time.Time"
+	}
+	return "ColumnType(" + strconv.Itoa(int(t)) + ")"
+}
+
+// timeLayouts layouts InferSchema tries, in order, for time columns
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	"01/02/2006",
+}
+
+// Column a column of an inferred schema. Nullable is set if any sampled
+// value was empty, Format is the time layout of time columns.
+type Column struct {
+	Name     string
+	Type     ColumnType
+	Nullable bool
+	Format   string
+}
+
+// Schema columns of a CSV file in header order
+type Schema struct {
+	Columns []Column
+}
+
+// InferSchema guess the type of every column from the header and up to
+// sampleRows rows of reader, all rows are sampled if sampleRows is 0 or
+// less. A column is the narrowest of int, float, bool and time every
+// non-empty sampled value parses as, otherwise string.
+func InferSchema(reader *csv.Reader, sampleRows int) (*Schema, error) {
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("error reading header: %w", err)
+	}
+
+	guesses := make([]*columnGuess, len(header))
+	for i := range guesses {
+		guesses[i] = newColumnGuess()
+	}
+
+	for n := 0; sampleRows <= 0 || n < sampleRows; n++ {
+		row, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		for i, guess := range guesses {
+			if i < len(row) {
+				guess.observe(row[i])
+			} else {
+				guess.nullable = true
+			}
+		}
+	}
+
+	schema := &Schema{
+		Columns: make([]Column, len(header)),
+	}
+	for i, name := range header {
+		schema.Columns[i] = guesses[i].column(strings.TrimSpace(name))
+	}
+	return schema, nil
+}
+
+// columnGuess types every value seen so far in a column parses as
+type columnGuess struct {
+	seen     bool
+	nullable bool
+	isInt    bool
+	isFloat  bool
+	isBool   bool
+	layouts  []string
+}
+
+func newColumnGuess() *columnGuess {
+	return &columnGuess{
+		isInt:   true,
+		isFloat: true,
+		isBool:  true,
+		layouts: timeLayouts,
+	}
+}
+
+func (t *columnGuess) observe(value string) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		t.nullable = true
+		return
+	}
+	t.seen = true
+
+	if t.isInt {
+		_, err := strconv.ParseInt(value, 10, 64)
+		t.isInt = err == nil
+	}
+	if t.isFloat {
+		_, err := strconv.ParseFloat(value, 64)
+		t.isFloat = err == nil
+	}
+	if t.isBool {
+		_, err := strconv.ParseBool(value)
+		t.isBool = err == nil
+	}
+
+	var layouts []string
+	for _, layout := range t.layouts {
+		if _, err := time.Parse(layout, value); err == nil {
+			layouts = append(layouts, layout)
+		}
+	}
+	t.layouts = layouts
+}
+
+func (t *columnGuess) column(name string) Column {
+	column := Column{
+		Name:     name,
+		Type:     StringColumn,
+		Nullable: t.nullable,
+	}
+
+	// Columns with no values are left as strings
+	if !t.seen {
+		return column
+	}
+
+	switch {
+	case t.isInt:
+		column.Type = IntColumn
+	case t.isFloat:
+		column.Type = FloatColumn
+	case t.isBool:
+		column.Type = BoolColumn
+	case len(t.layouts) > 0:
+		column.Type = TimeColumn
+		column.Format = t.layouts[0]
+	}
+	return column
+}
+
+// GoStruct a gofmt'd Go struct definition named name that Reader can read
+// the file into, nullable columns are pointers. A column name the tag cannot
+// hold, with a comma or that parses as a column number, is matched by a
+// quoted alias instead. Names that cannot be quoted are an error.
+func (t *Schema) GoStruct(name string) (string, error) {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "type %s struct {\n", goIdentifier(name))
+
+	used := make(map[string]bool)
+	for _, column := range t.Columns {
+		field := uniqueName(goIdentifier(column.Name), "", used)
+
+		typ := column.Type.String()
+		if column.Nullable {
+			typ = "*" + typ
+		}
+
+		tag, err := columnTag(field, column.Name)
+		if err != nil {
+			return "", err
+		}
+		if column.Type == TimeColumn && column.Format != defaultDateFormat {
+			tag += ",format=" + column.Format
+		}
+
+		fmt.Fprintf(buf, "%s %s `csv:%q`\n", field, typ, tag)
+	}
+	buf.WriteString("}\n")
+
+	src, err := format.Source([]byte(buf.String()))
+	if err != nil {
+		return "", err
+	}
+	return string(src), nil
+}
+
+// columnTag the csv tag name reading column name into field
+func columnTag(field, name string) (string, error) {
+	if strings.Contains(name, "`") {
+		return "", fmt.Errorf("column %q cannot be used in a struct tag", name)
+	}
+
+	_, err := strconv.Atoi(name)
+	if err != nil && name != "-" && !strings.Contains(name, ",") {
+		return name, nil
+	}
+
+	if strings.ContainsAny(name, "'|") {
+		return "", fmt.Errorf("column %q cannot be used in a struct tag", name)
+	}
+	return field + ",alias='" + name + "'", nil
+}
+
+// uniqueName name, or name followed by sep and a number from 2 if it is
+// already used, the result is marked as used
+func uniqueName(name, sep string, used map[string]bool) string {
+	for n, base := 2, name; used[name]; n++ {
+		name = base + sep + strconv.Itoa(n)
+	}
+	used[name] = true
+	return name
+}
+
+// CreateTable a Postgres CREATE TABLE statement for the schema, columns are
+// named in snake case and are NOT NULL unless nullable. Names that collide
+// once snake cased are numbered like GoStruct fields, user_id then user_id_2.
+func (t *Schema) CreateTable(table string) string {
+	buf := &strings.Builder{}
+	fmt.Fprintf(buf, "CREATE TABLE %s (\n", quoteIdentifier(table))
+
+	used := make(map[string]bool)
+	for i, column := range t.Columns {
+		name := uniqueName(snakeCase(column.Name), "_", used)
+		fmt.Fprintf(buf, "\t%s %s", quoteIdentifier(name), postgresType(column))
+		if !column.Nullable {
+			buf.WriteString(" NOT NULL")
+		}
+		if i < len(t.Columns)-1 {
+			buf.WriteString(",")
+		}
+		buf.WriteString("\n")
+	}
+
+	buf.WriteString(");\n")
+	return buf.String()
+}
+
+func postgresType(column Column) string {
+	switch column.Type {
+	case IntColumn:
+		return "BIGINT"
+	case FloatColumn:
+		return "DOUBLE PRECISION"
+	case BoolColumn:
+		return "BOOLEAN"
+	case TimeColumn:
+		switch column.Format {
+		case "2006-01-02", "01/02/2006":
+			return "DATE"
+		case time.RFC3339:
+			return "TIMESTAMPTZ"
+		}
+		return "TIMESTAMP"
+	}
+	return "TEXT"
+}
+
+// words split a column name on anything that is not a letter or digit
+func words(name string) []string {
+	return strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// goIdentifier exported Go identifier for a column name, "user id" becomes
+// UserId
+func goIdentifier(name string) string {
+	buf := &strings.Builder{}
+	for _, word := range words(name) {
+		runes := []rune(word)
+		buf.WriteRune(unicode.ToUpper(runes[0]))
+		buf.WriteString(string(runes[1:]))
+	}
+
+	ident := buf.String()
+	if ident == "" {
+		return "Column"
+	}
+	if !unicode.IsLetter([]rune(ident)[0]) {
+		ident = "X" + ident
+	}
+	return ident
+}
+
+// snakeCase lower snake case column name, "User ID" becomes user_id
+func snakeCase(name string) string {
+	parts := words(name)
+	for i, part := range parts {
+		parts[i] = strings.ToLower(part)
+	}
+	if len(parts) == 0 {
+		return "column"
+	}
+	return strings.Join(parts, "_")
+}
+
+// quoteIdentifier quote a Postgres identifier
+func quoteIdentifier(name string) string {
+	return `"` + strings.ReplaceAll(name, `"`, `""`) + `"`
+}
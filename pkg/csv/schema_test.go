@@ -0,0 +1,160 @@
+package csv
+
+import (
+	"encoding/csv"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SchemaSuite struct{}
+
+var _ = Suite(&SchemaSuite{})
+
+const schemaCSV = `User ID,name,score,active,created,joined,notes
+1,alice,1.5,true,2021-01-01T00:00:00Z,2021-01-01,
+2,bob,2,false,2021-02-02T10:30:00Z,2021-02-02,late
+3,carol,,TRUE,2021-03-03T00:00:00Z,2021-03-03,
+`
+
+func (s *SchemaSuite) inferSchema(c *C, sampleRows int) *Schema {
+	schema, err := InferSchema(csv.NewReader(strings.NewReader(schemaCSV)), sampleRows)
+	c.Assert(err, IsNil)
+	return schema
+}
+
+func (s *SchemaSuite) Test_InferSchema(c *C) {
+	schema := s.inferSchema(c, 0)
+
+	c.Assert(schema.Columns, DeepEquals, []Column{
+		{Name: "User ID", Type: IntColumn},
+		{Name: "name", Type: StringColumn},
+		{Name: "score", Type: FloatColumn, Nullable: true},
+		{Name: "active", Type: BoolColumn},
+		{Name: "created", Type: TimeColumn, Format: "2006-01-02T15:04:05Z07:00"},
+		{Name: "joined", Type: TimeColumn, Format: "2006-01-02"},
+		{Name: "notes", Type: StringColumn, Nullable: true},
+	})
+}
+
+func (s *SchemaSuite) Test_InferSchema_Sample(c *C) {
+	schema := s.inferSchema(c, 1)
+
+	c.Assert(schema.Columns[2], DeepEquals, Column{Name: "score", Type: FloatColumn})
+	c.Assert(schema.Columns[6], DeepEquals, Column{Name: "notes", Type: StringColumn, Nullable: true})
+}
+
+func (s *SchemaSuite) Test_GoStruct(c *C) {
+	src, err := s.inferSchema(c, 0).GoStruct("user")
+	c.Assert(err, IsNil)
+	c.Assert(src, Equals, "type User struct {\n"+
+		"\tUserID  int64     `csv:\"User ID\"`\n"+
+		"\tName    string    `csv:\"name\"`\n"+
+		"\tScore   *float64  `csv:\"score\"`\n"+
+		"\tActive  bool      `csv:\"active\"`\n"+
+		"\tCreated time.Time `csv:\"created\"`\n"+
+		"\tJoined  time.Time `csv:\"joined,format=2006-01-02\"`\n"+
+		"\tNotes   *string   `csv:\"notes\"`\n"+
+		"}\n")
+}
+
+func (s *SchemaSuite) Test_GoStruct_Names(c *C) {
+	schema := &Schema{Columns: []Column{
+		{Name: "Id"},
+		{Name: "id"},
+		{Name: "Id2"},
+		{Name: "2024"},
+		{Name: "city, state"},
+		{Name: "-"},
+	}}
+
+	src, err := schema.GoStruct("row")
+	c.Assert(err, IsNil)
+	c.Assert(src, Equals, "type Row struct {\n"+
+		"\tId        string `csv:\"Id\"`\n"+
+		"\tId2       string `csv:\"id\"`\n"+
+		"\tId22      string `csv:\"Id2\"`\n"+
+		"\tX2024     string `csv:\"X2024,alias='2024'\"`\n"+
+		"\tCityState string `csv:\"CityState,alias='city, state'\"`\n"+
+		"\tColumn    string `csv:\"Column,alias='-'\"`\n"+
+		"}\n")
+
+	type row struct {
+		Id        string `csv:"Id"`
+		X2024     string `csv:"X2024,alias='2024'"`
+		CityState string `csv:"CityState,alias='city, state'"`
+		Column    string `csv:"Column,alias='-'"`
+	}
+
+	reader := NewReader[row]()
+	reader.HasHeader = true
+	reader.Strict = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader("Id,2024,\"city, state\",-\na,b,c,d\n"))
+	c.Assert(err, IsNil)
+
+	rows, errs := drain(outCh, errCh)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, DeepEquals, []row{{"a", "b", "c", "d"}})
+
+	_, err = (&Schema{Columns: []Column{{Name: "a,'b'"}}}).GoStruct("row")
+	c.Assert(err, ErrorMatches, `column "a,'b'" cannot be used in a struct tag`)
+}
+
+func (s *SchemaSuite) Test_GoStruct_Reads(c *C) {
+	type user struct {
+		UserID int64      `csv:"User ID"`
+		Score  *float64   `csv:"score"`
+		Joined *time.Time `csv:"joined,format=2006-01-02"`
+	}
+
+	reader := NewReader[user]()
+	reader.HasHeader = true
+	reader.Strict = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader(schemaCSV))
+	c.Assert(err, IsNil)
+
+	rows, errs := drain(outCh, errCh)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, HasLen, 3)
+	c.Assert(rows[2].Score, IsNil)
+	c.Assert(rows[1].Joined.Format("2006-01-02"), Equals, "2021-02-02")
+}
+
+func (s *SchemaSuite) Test_CreateTable(c *C) {
+	c.Assert(s.inferSchema(c, 0).CreateTable("users"), Equals, `CREATE TABLE "users" (
+	"user_id" BIGINT NOT NULL,
+	"name" TEXT NOT NULL,
+	"score" DOUBLE PRECISION,
+	"active" BOOLEAN NOT NULL,
+	"created" TIMESTAMPTZ NOT NULL,
+	"joined" DATE NOT NULL,
+	"notes" TEXT
+);
+`)
+}
+
+func (s *SchemaSuite) Test_CreateTable_Names(c *C) {
+	schema := &Schema{Columns: []Column{
+		{Name: "User ID", Type: IntColumn},
+		{Name: "user_id", Type: IntColumn},
+		{Name: "user id 2", Type: StringColumn, Nullable: true},
+	}}
+
+	c.Assert(schema.CreateTable("t"), Equals, `CREATE TABLE "t" (
+	"user_id" BIGINT NOT NULL,
+	"user_id_2" BIGINT NOT NULL,
+	"user_id_2_2" TEXT
+);
+`)
+}
+
+func (s *SchemaSuite) Test_Identifiers(c *C) {
+	c.Assert(goIdentifier("user id"), Equals, "UserId")
+	c.Assert(goIdentifier("2fa"), Equals, "X2fa")
+	c.Assert(goIdentifier("--"), Equals, "Column")
+	c.Assert(snakeCase("User ID"), Equals, "user_id")
+	c.Assert(quoteIdentifier(`a"b`), Equals, `"a""b"`)
+}
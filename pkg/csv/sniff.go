@@ -0,0 +1,420 @@
+package csv
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/unicode"
+)
+
+const (
+	// sniffSize bytes of input Sniff examines
+	sniffSize = 64 << 10
+
+	// sniffRows records Sniff examines for a header
+	sniffRows = 20
+)
+
+// Encoding character encoding of a file
+type Encoding int
+
+const (
+	UTF8 Encoding = iota
+	UTF8BOM
+	UTF16LE
+	UTF16BE
+	Latin1
+)
+
+func (t Encoding) String() string {
+	switch t {
+	case UTF8:
+		return "UTF-8"
+	case UTF8BOM:
+		return "UTF-8 BOM"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF16BE:
+		return "UTF-16BE"
+	case Latin1:
+		return "ISO-8859-1"
+	}
+	return "Encoding(" + strconv.Itoa(int(t)) + ")"
+}
+
+var (
+	// delimiters candidates Sniff tries, in order of preference
+	delimiters = []rune{',', '\t', ';', '|', ':'}
+
+	// quotes candidates Sniff tries, in order of preference
+	quotes = []rune{'"', '\''}
+
+	utf8BOM    = []byte{0xef, 0xbb, 0xbf}
+	utf16LEBOM = []byte{0xff, 0xfe}
+	utf16BEBOM = []byte{0xfe, 0xff}
+)
+
+// Dialect how a CSV file is written
+type Dialect struct {
+	Comma      rune
+	Quote      rune
+	HasHeader  bool
+	Terminator string
+	Encoding   Encoding
+}
+
+// Sniff detect the dialect of the CSV in reader from its first 64KiB. The
+// returned reader reads the whole input converted to what encoding/csv
+// expects: UTF-8 without a byte order mark, '"' quotes and "\n" or "\r\n"
+// line endings. The delimiter is the candidate that splits every sampled
+// line into the same number of fields, a header is detected when its cells
+// do not parse as the type of the values below them.
+func Sniff(reader io.Reader) (*Dialect, io.Reader, error) {
+	buffered := bufio.NewReaderSize(reader, sniffSize)
+
+	sample, err := buffered.Peek(sniffSize)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, nil, err
+	}
+	truncated := len(sample) == sniffSize
+
+	dialect := &Dialect{
+		Comma:    Comma,
+		Quote:    '"',
+		Encoding: sniffEncoding(sample),
+	}
+
+	switch dialect.Encoding {
+	case UTF8BOM:
+		buffered.Discard(len(utf8BOM))
+	case UTF16LE, UTF16BE:
+		if bytes.HasPrefix(sample, utf16LEBOM) || bytes.HasPrefix(sample, utf16BEBOM) {
+			buffered.Discard(len(utf16LEBOM))
+		}
+	}
+
+	// The rest of the analysis is on the sample decoded to UTF-8
+	decoded, err := io.ReadAll(decode(bytes.NewReader(sample), dialect.Encoding))
+	if err != nil {
+		return nil, nil, err
+	}
+	text := strings.TrimPrefix(string(decoded), "\ufeff")
+
+	dialect.Terminator = sniffTerminator(text)
+
+	lines := strings.Split(strings.ReplaceAll(strings.ReplaceAll(text, "\r\n", "\n"), "\r", "\n"), "\n")
+	if truncated && len(lines) > 1 {
+		lines = lines[:len(lines)-1]
+	}
+	if len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	dialect.Quote = sniffQuote(lines)
+	dialect.Comma = sniffDelimiter(lines, dialect.Quote)
+
+	records := sampleRecords(strings.Join(lines, "\n"), dialect)
+	dialect.HasHeader = sniffHeader(records)
+
+	return dialect, dialect.normalize(decode(buffered, dialect.Encoding)), nil
+}
+
+// sniffEncoding encoding from the byte order mark, the position of zero
+// bytes or whether the sample is valid UTF-8
+func sniffEncoding(sample []byte) Encoding {
+	switch {
+	case bytes.HasPrefix(sample, utf8BOM):
+		return UTF8BOM
+	case bytes.HasPrefix(sample, utf16LEBOM):
+		return UTF16LE
+	case bytes.HasPrefix(sample, utf16BEBOM):
+		return UTF16BE
+	}
+
+	// ASCII text in UTF-16 has a zero in every other byte
+	var even, odd int
+	for i, b := range sample {
+		if b != 0 {
+			continue
+		}
+		if i%2 == 0 {
+			even++
+		} else {
+			odd++
+		}
+	}
+	if odd > len(sample)/4 && odd > even {
+		return UTF16LE
+	}
+	if even > len(sample)/4 && even > odd {
+		return UTF16BE
+	}
+
+	// The sample may end part way through a rune
+	if len(sample) == sniffSize {
+		for i := len(sample) - 1; i >= 0 && i >= len(sample)-utf8.UTFMax; i-- {
+			if utf8.RuneStart(sample[i]) {
+				if !utf8.FullRune(sample[i:]) {
+					sample = sample[:i]
+				}
+				break
+			}
+		}
+	}
+
+	if utf8.Valid(sample) {
+		return UTF8
+	}
+	return Latin1
+}
+
+// decode reader from encoding to UTF-8
+func decode(reader io.Reader, encoding Encoding) io.Reader {
+	switch encoding {
+	case UTF16LE:
+		return unicode.UTF16(unicode.LittleEndian, unicode.IgnoreBOM).NewDecoder().Reader(reader)
+	case UTF16BE:
+		return unicode.UTF16(unicode.BigEndian, unicode.IgnoreBOM).NewDecoder().Reader(reader)
+	case Latin1:
+		return charmap.ISO8859_1.NewDecoder().Reader(reader)
+	}
+	return reader
+}
+
+// sniffTerminator most common of "\r\n", "\n" and a lone "\r"
+func sniffTerminator(text string) string {
+	crlf := strings.Count(text, "\r\n")
+	lf := strings.Count(text, "\n") - crlf
+	cr := strings.Count(text, "\r") - crlf
+
+	switch {
+	case crlf > 0 && crlf >= lf && crlf >= cr:
+		return "\r\n"
+	case cr > lf:
+		return "\r"
+	}
+	return "\n"
+}
+
+// sniffQuote the candidate seen most often at the edge of a field
+func sniffQuote(lines []string) rune {
+	best, bestCount := quotes[0], 0
+
+	for _, quote := range quotes {
+		var count int
+		for _, line := range lines {
+			if strings.HasPrefix(line, string(quote)) {
+				count++
+			}
+			if strings.HasSuffix(line, string(quote)) {
+				count++
+			}
+			for _, delimiter := range delimiters {
+				count += strings.Count(line, string(delimiter)+string(quote))
+				count += strings.Count(line, string(quote)+string(delimiter))
+			}
+		}
+
+		if count > bestCount {
+			best, bestCount = quote, count
+		}
+	}
+	return best
+}
+
+// sniffDelimiter the candidate that splits the most lines into the same
+// number of fields, at least two
+func sniffDelimiter(lines []string, quote rune) rune {
+	best, bestScore := Comma, 0.0
+
+	for _, delimiter := range delimiters {
+		counts := make(map[int]int)
+		for _, line := range lines {
+			counts[countFields(line, delimiter, quote)]++
+		}
+
+		var mode, modeLines int
+		for fields, n := range counts {
+			if n > modeLines || (n == modeLines && fields > mode) {
+				mode, modeLines = fields, n
+			}
+		}
+		if mode < 2 {
+			continue
+		}
+
+		score := float64(modeLines) / float64(len(lines))
+		if score > bestScore {
+			best, bestScore = delimiter, score
+		}
+	}
+	return best
+}
+
+// countFields fields in line, delimiters inside quotes are not counted
+func countFields(line string, delimiter, quote rune) int {
+	fields := 1
+	quoted := false
+	for _, r := range line {
+		switch r {
+		case quote:
+			quoted = !quoted
+		case delimiter:
+			if !quoted {
+				fields++
+			}
+		}
+	}
+	return fields
+}
+
+// sampleRecords parse up to sniffRows records of text
+func sampleRecords(text string, dialect *Dialect) [][]string {
+	reader := csv.NewReader(dialect.normalize(strings.NewReader(text)))
+	reader.Comma = dialect.Comma
+	reader.LazyQuotes = true
+	reader.FieldsPerRecord = -1
+
+	var records [][]string
+	for len(records) < sniffRows {
+		record, err := reader.Read()
+		if err != nil {
+			break
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// sniffHeader whether the first record is a header. Each column votes for a
+// header if its first cell does not parse as the type of the cells below,
+// and against if it does or the first cell repeats one below.
+func sniffHeader(records [][]string) bool {
+	if len(records) < 2 {
+		return false
+	}
+
+	header := records[0]
+	var votes int
+
+	for col, name := range header {
+		guess := newColumnGuess()
+		repeated := false
+		for _, record := range records[1:] {
+			if col < len(record) {
+				guess.observe(record[col])
+				repeated = repeated || record[col] == name
+			}
+		}
+
+		column := guess.column(name)
+		switch {
+		case repeated:
+			votes--
+		case column.Type == StringColumn:
+		case parsesAs(name, column):
+			votes--
+		default:
+			votes++
+		}
+	}
+	return votes > 0
+}
+
+// parsesAs whether value parses as the type of column
+func parsesAs(value string, column Column) bool {
+	guess := newColumnGuess()
+	guess.observe(value)
+	if !guess.seen {
+		return false
+	}
+
+	switch column.Type {
+	case IntColumn:
+		return guess.isInt
+	case FloatColumn:
+		return guess.isFloat
+	case BoolColumn:
+		return guess.isBool
+	case TimeColumn:
+		for _, layout := range guess.layouts {
+			if layout == column.Format {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+// normalize reader, which is UTF-8, into the quotes and line endings
+// encoding/csv reads
+func (t *Dialect) normalize(reader io.Reader) io.Reader {
+	if t.Quote == '"' && t.Terminator != "\r" {
+		return reader
+	}
+
+	return &dialectReader{
+		reader:  bufio.NewReader(reader),
+		quote:   t.Quote,
+		onlyCR:  t.Terminator == "\r",
+		pending: make([]byte, 0, utf8.UTFMax*2),
+	}
+}
+
+// dialectReader rewrites lone "\r" line endings as "\n" and quote as '"',
+// escaping any '"' inside a quoted field
+type dialectReader struct {
+	reader  *bufio.Reader
+	quote   rune
+	onlyCR  bool
+	quoted  bool
+	pending []byte
+}
+
+func (t *dialectReader) Read(p []byte) (int, error) {
+	n := copy(p, t.pending)
+	t.pending = t.pending[n:]
+
+	for n < len(p) {
+		r, _, err := t.reader.ReadRune()
+		if err != nil {
+			if n > 0 {
+				return n, nil
+			}
+			return 0, err
+		}
+
+		out := t.pending[:0]
+		switch {
+		case t.onlyCR && r == '\r':
+			out = append(out, '\n')
+		case r == t.quote:
+			t.quoted = !t.quoted
+			out = append(out, '"')
+		case r == '"' && t.quoted:
+			out = append(out, '"', '"')
+		default:
+			out = utf8.AppendRune(out, r)
+		}
+
+		c := copy(p[n:], out)
+		n += c
+		t.pending = out[c:]
+	}
+	return n, nil
+}
+
+// SetDialect read files written in dialect, the input must be the reader
+// returned by Sniff
+func (t *Reader[T]) SetDialect(dialect *Dialect) {
+	t.Comma = dialect.Comma
+	t.HasHeader = dialect.HasHeader
+}
@@ -0,0 +1,184 @@
+package csv
+
+import (
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	. "gopkg.in/check.v1"
+)
+
+type SniffSuite struct{}
+
+var _ = Suite(&SniffSuite{})
+
+type sniffRow struct {
+	Name   string  `csv:"name"`
+	Amount float64 `csv:"amount"`
+	Date   string  `csv:"date"`
+}
+
+const sniffCSV = "name,amount,date\nfoo,1.5,2021-01-01\n\"bar, baz\",2,2021-02-02\n"
+
+func (s *SniffSuite) sniff(c *C, data string) (*Dialect, string) {
+	dialect, reader, err := Sniff(strings.NewReader(data))
+	c.Assert(err, IsNil)
+
+	b, err := io.ReadAll(reader)
+	c.Assert(err, IsNil)
+	return dialect, string(b)
+}
+
+func utf16LE(s string, bom bool) string {
+	var b []byte
+	if bom {
+		b = append(b, 0xff, 0xfe)
+	}
+	for _, unit := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(unit), byte(unit>>8))
+	}
+	return string(b)
+}
+
+func (s *SniffSuite) Test_Sniff_Delimiter(c *C) {
+	for _, comma := range []string{",", "\t", ";", "|"} {
+		data := strings.ReplaceAll("name,amount\nfoo,1\nbar,2\n", ",", comma)
+
+		dialect, out := s.sniff(c, data)
+		c.Assert(dialect.Comma, Equals, []rune(comma)[0], Commentf("comma %q", comma))
+		c.Assert(out, Equals, data)
+	}
+
+	// Decimal commas inside semicolon separated values
+	dialect, _ := s.sniff(c, "name;amount\nfoo;1,5\nbar;2,25\n")
+	c.Assert(dialect.Comma, Equals, ';')
+
+	// Delimiters inside quotes are not counted
+	dialect, _ = s.sniff(c, "a;b\n\"x;y;z\";1\n\"w\";2\n")
+	c.Assert(dialect.Comma, Equals, ';')
+}
+
+func (s *SniffSuite) Test_Sniff_Header(c *C) {
+	dialect, _ := s.sniff(c, sniffCSV)
+	c.Assert(dialect.HasHeader, Equals, true)
+	c.Assert(dialect.Comma, Equals, ',')
+	c.Assert(dialect.Quote, Equals, '"')
+	c.Assert(dialect.Terminator, Equals, "\n")
+	c.Assert(dialect.Encoding, Equals, UTF8)
+
+	dialect, _ = s.sniff(c, "foo,1.5,2021-01-01\nbar,2,2021-02-02\n")
+	c.Assert(dialect.HasHeader, Equals, false)
+}
+
+func (s *SniffSuite) Test_Sniff_Quote(c *C) {
+	dialect, out := s.sniff(c, "name,note\n'a, b','say \"hi\"'\n'c','it''s'\n")
+	c.Assert(dialect.Quote, Equals, '\'')
+	c.Assert(out, Equals, "name,note\n\"a, b\",\"say \"\"hi\"\"\"\n\"c\",\"it\"\"s\"\n")
+}
+
+func (s *SniffSuite) Test_Sniff_Terminator(c *C) {
+	dialect, out := s.sniff(c, "name,amount\r\nfoo,1\r\n")
+	c.Assert(dialect.Terminator, Equals, "\r\n")
+	c.Assert(out, Equals, "name,amount\r\nfoo,1\r\n")
+
+	dialect, out = s.sniff(c, "name,amount\rfoo,1\rbar,2\r")
+	c.Assert(dialect.Terminator, Equals, "\r")
+	c.Assert(out, Equals, "name,amount\nfoo,1\nbar,2\n")
+}
+
+func (s *SniffSuite) Test_Sniff_Encoding(c *C) {
+	dialect, out := s.sniff(c, "\xef\xbb\xbf"+sniffCSV)
+	c.Assert(dialect.Encoding, Equals, UTF8BOM)
+	c.Assert(dialect.HasHeader, Equals, true)
+	c.Assert(out, Equals, sniffCSV)
+
+	dialect, out = s.sniff(c, utf16LE("café,1\nnaïve,2\n", true))
+	c.Assert(dialect.Encoding, Equals, UTF16LE)
+	c.Assert(out, Equals, "café,1\nnaïve,2\n")
+
+	dialect, out = s.sniff(c, utf16LE(sniffCSV, false))
+	c.Assert(dialect.Encoding, Equals, UTF16LE)
+	c.Assert(dialect.HasHeader, Equals, true)
+	c.Assert(out, Equals, sniffCSV)
+
+	dialect, out = s.sniff(c, "name;amount\ncaf\xe9;1\n")
+	c.Assert(dialect.Encoding, Equals, Latin1)
+	c.Assert(dialect.Comma, Equals, ';')
+	c.Assert(out, Equals, "name;amount\ncafé;1\n")
+
+	c.Assert(UTF16BE.String(), Equals, "UTF-16BE")
+}
+
+func (s *SniffSuite) Test_Sniff_Reader(c *C) {
+	data := "\xef\xbb\xbfname;amount;date\rfoo;1.5;2021-01-01\rbar;2;2021-02-02\r"
+
+	dialect, input, err := Sniff(strings.NewReader(data))
+	c.Assert(err, IsNil)
+
+	reader := NewReader[sniffRow]()
+	reader.SetDialect(dialect)
+	reader.Strict = true
+
+	outCh, errCh, err := reader.Stream(input)
+	c.Assert(err, IsNil)
+
+	rows, errs := drain(outCh, errCh)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(rows, DeepEquals, []sniffRow{
+		{Name: "foo", Amount: 1.5, Date: "2021-01-01"},
+		{Name: "bar", Amount: 2, Date: "2021-02-02"},
+	})
+}
+
+func (s *SniffSuite) stream(c *C, reader *Reader[sniffRow], data string) ([]sniffRow, []error) {
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.StreamFile("partner.csv", strings.NewReader(data))
+	c.Assert(err, IsNil)
+	return drain(outCh, errCh)
+}
+
+func (s *SniffSuite) Test_Reader_Options(c *C) {
+	data := "Partner report\ngenerated today\nname\tamount\tdate\n# a comment\nfoo\t1\tx\"y\nbar\tbad\tz\n"
+
+	reader := NewReader[sniffRow]()
+	reader.Comma = Tab
+	reader.SkipRows = 2
+	reader.Comment = '#'
+	reader.LazyQuotes = true
+
+	rows, errs := s.stream(c, reader, data)
+	c.Assert(rows, DeepEquals, []sniffRow{{Name: "foo", Amount: 1, Date: "x\"y"}})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `partner.csv:6: column "amount".*`)
+
+	reader.LazyQuotes = false
+	rows, errs = s.stream(c, reader, data)
+	c.Assert(rows, HasLen, 0)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, `partner.csv:5: .*bare " in non-quoted-field`)
+
+	reader.Workers = 2
+	reader.ChunkSize = 8
+	reader.Ordered = true
+	reader.LazyQuotes = true
+
+	outCh, errCh, err := reader.StreamParallel("partner.csv", strings.NewReader(data))
+	c.Assert(err, IsNil)
+	rows, errs = drain(outCh, errCh)
+	c.Assert(rows, DeepEquals, []sniffRow{{Name: "foo", Amount: 1, Date: "x\"y"}})
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, `partner.csv:6: column "amount".*`)
+}
+
+func (s *SniffSuite) Test_SkipLines(c *C) {
+	reader, err := skipLines(strings.NewReader(strings.Repeat("x", 5000)+"\nrest"), 1)
+	c.Assert(err, IsNil)
+	b, _ := io.ReadAll(reader)
+	c.Assert(string(b), Equals, "rest")
+
+	reader, err = skipLines(strings.NewReader("a\n"), 3)
+	c.Assert(err, IsNil)
+	b, _ = io.ReadAll(reader)
+	c.Assert(string(b), Equals, "")
+}
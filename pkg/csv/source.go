@@ -0,0 +1,235 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sjhitchner/toolbox/pkg/fileutils"
+	"github.com/sjhitchner/toolbox/pkg/streaming"
+)
+
+// Record a row and the file it was read from
+type Record[T any] struct {
+	File string
+	Row  T
+}
+
+// sendError send err unless ctx is cancelled first, returns false if it was
+func sendError(ctx context.Context, errCh chan<- error, err error) bool {
+	select {
+	case errCh <- err:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// copyErrors forward in to out until in closes, once ctx is cancelled the
+// rest of in is discarded. The returned channel closes when in has closed.
+func copyErrors(ctx context.Context, out chan<- error, in <-chan error) <-chan struct{} {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for err := range in {
+			// Cancellation takes priority over a ready consumer
+			if ctx.Err() == nil {
+				sendError(ctx, out, err)
+			}
+		}
+	}()
+	return done
+}
+
+// contextReader fails every read once ctx is cancelled, stopping a parser
+// between reads
+type contextReader struct {
+	ctx    context.Context
+	reader io.Reader
+}
+
+func (t *contextReader) Read(p []byte) (int, error) {
+	if err := t.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return t.reader.Read(p)
+}
+
+// isRemote whether source is an object store URL read by fileutils.Open
+func isRemote(source string) bool {
+	return strings.HasPrefix(source, "s3://") || strings.HasPrefix(source, "gs://")
+}
+
+// ExpandSources the files named by sources. s3:// and gs:// URLs are passed
+// through as given, globs are expanded, directories are walked for files
+// that pass filter once any compression extension is trimmed, and other
+// paths are passed through whether or not they pass filter. A nil filter
+// accepts every file.
+func ExpandSources(ctx context.Context, filter FilterFunc, sources ...string) (<-chan string, <-chan error) {
+	fileCh := make(chan string)
+	errCh := make(chan error)
+
+	if filter == nil {
+		filter = func(string) bool { return true }
+	}
+
+	send := func(filename string) error {
+		select {
+		case fileCh <- filename:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	// expand one local path, returns ctx.Err() if cancelled
+	var expand func(path string) error
+	expand = func(path string) error {
+		info, err := os.Stat(path)
+		if err != nil {
+			if !sendError(ctx, errCh, err) {
+				return ctx.Err()
+			}
+			return nil
+		}
+
+		if !info.IsDir() {
+			return send(path)
+		}
+
+		return filepath.WalkDir(path, func(filename string, d fs.DirEntry, err error) error {
+			if err != nil {
+				if !sendError(ctx, errCh, err) {
+					return ctx.Err()
+				}
+				return nil
+			}
+			if d.IsDir() || !filter(TrimCompression(filename)) {
+				return nil
+			}
+			return send(filename)
+		})
+	}
+
+	go func() {
+		defer close(fileCh)
+		defer close(errCh)
+
+		for _, source := range sources {
+			if isRemote(source) {
+				if send(source) != nil {
+					return
+				}
+				continue
+			}
+
+			if !strings.ContainsAny(source, "*?[") {
+				if expand(source) != nil {
+					return
+				}
+				continue
+			}
+
+			matches, err := filepath.Glob(source)
+			if err != nil {
+				if !sendError(ctx, errCh, fmt.Errorf("%s: %w", source, err)) {
+					return
+				}
+				continue
+			}
+			if len(matches) == 0 && !sendError(ctx, errCh, fmt.Errorf("%s: no files match", source)) {
+				return
+			}
+			for _, match := range matches {
+				if expand(match) != nil {
+					return
+				}
+			}
+		}
+	}()
+
+	return fileCh, errCh
+}
+
+// Walk read every file named by sources, see ExpandSources, with the
+// reader's Filter or IsCSV if it has none. Files are opened with
+// fileutils.Open, decompressed as needed and closed once read. Each row is
+// tagged with the file it came from. Files that cannot be opened are
+// reported and skipped, cancelling ctx stops the walk and the file being
+// read, errors not yet received are then discarded.
+func (t *Reader[T]) Walk(ctx context.Context, sources ...string) (<-chan Record[T], <-chan error) {
+	outCh := make(chan Record[T])
+	errCh := make(chan error)
+
+	filter := t.Filter
+	if filter == nil {
+		filter = IsCSV
+	}
+
+	fileCh, expandErrCh := ExpandSources(ctx, filter, sources...)
+	expandDone := copyErrors(ctx, errCh, expandErrCh)
+
+	go func() {
+		defer close(errCh)
+		defer close(outCh)
+
+		for filename := range fileCh {
+			t.walkFile(ctx, filename, outCh, errCh)
+		}
+
+		// Errors from expanding sources must be sent before errCh closes
+		<-expandDone
+	}()
+
+	return outCh, errCh
+}
+
+// walkFile send every row of filename to outCh and its errors to errCh
+func (t *Reader[T]) walkFile(ctx context.Context, filename string, outCh chan<- Record[T], errCh chan<- error) {
+	if ctx.Err() != nil {
+		return
+	}
+
+	file, err := fileutils.Open(ctx, filename)
+	if err != nil {
+		sendError(ctx, errCh, fmt.Errorf("%s: %w", filename, err))
+		return
+	}
+
+	reader, err := Decompress(filename, file)
+	if err != nil {
+		sendError(ctx, errCh, fmt.Errorf("%s: %w", filename, err))
+		return
+	}
+	defer reader.Close()
+
+	// Once ctx is cancelled the parser fails its next read and stops, the
+	// rows it has already buffered are discarded
+	ch, eCh, err := t.StreamFile(filename, &contextReader{ctx: ctx, reader: reader})
+	if err != nil {
+		sendError(ctx, errCh, err)
+		return
+	}
+	errsDone := copyErrors(ctx, errCh, eCh)
+
+	for row := range ch {
+		// Cancellation takes priority over a ready consumer
+		if ctx.Err() != nil {
+			streaming.Consume(ch)
+			break
+		}
+
+		select {
+		case outCh <- Record[T]{File: filename, Row: row}:
+		case <-ctx.Done():
+			streaming.Consume(ch)
+		}
+	}
+
+	// The file is read once every error has been sent
+	<-errsDone
+}
@@ -0,0 +1,159 @@
+package csv
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type SourceSuite struct {
+	dir string
+}
+
+var _ = Suite(&SourceSuite{})
+
+type sourceRow struct {
+	Name   string `csv:"name"`
+	Amount int    `csv:"amount"`
+}
+
+func (s *SourceSuite) SetUpTest(c *C) {
+	s.dir = c.MkDir()
+
+	files := map[string][]byte{
+		"a.csv":            []byte("name,amount\na,1\n"),
+		"notes.txt.json":   []byte(`{"not": "csv"}`),
+		"nested/b.csv.gz":  gzipData(c, "name,amount\nb,2\n"),
+		"nested/c.csv.bz2": bzip2Data,
+		"nested/d.csv.zst": zstdData(c, "name,amount\nd,4\nbad,x\n"),
+	}
+
+	for name, data := range files {
+		filename := filepath.Join(s.dir, name)
+		c.Assert(os.MkdirAll(filepath.Dir(filename), 0o755), IsNil)
+		c.Assert(os.WriteFile(filename, data, 0o644), IsNil)
+	}
+}
+
+func (s *SourceSuite) path(name string) string {
+	return filepath.Join(s.dir, name)
+}
+
+func (s *SourceSuite) expand(c *C, filter FilterFunc, sources ...string) ([]string, []error) {
+	files, errs := drain(ExpandSources(context.Background(), filter, sources...))
+	sort.Strings(files)
+	return files, errs
+}
+
+func (s *SourceSuite) Test_ExpandSources(c *C) {
+	files, errs := s.expand(c, IsCSV, s.dir)
+	c.Assert(errs, HasLen, 0)
+	c.Assert(files, DeepEquals, []string{
+		s.path("a.csv"),
+		s.path("nested/b.csv.gz"),
+		s.path("nested/c.csv.bz2"),
+		s.path("nested/d.csv.zst"),
+	})
+}
+
+func (s *SourceSuite) Test_ExpandSources_Glob(c *C) {
+	files, errs := s.expand(c, IsCSV, s.path("nested/*.gz"), s.path("*.json"), "s3://bucket/key.csv")
+	c.Assert(errs, HasLen, 0)
+	c.Assert(files, DeepEquals, []string{
+		s.path("nested/b.csv.gz"),
+		s.path("notes.txt.json"),
+		"s3://bucket/key.csv",
+	})
+}
+
+func (s *SourceSuite) Test_ExpandSources_Missing(c *C) {
+	files, errs := s.expand(c, nil, s.path("missing.csv"), s.path("*.tsv"))
+	c.Assert(files, HasLen, 0)
+	c.Assert(errs, HasLen, 2)
+	c.Assert(os.IsNotExist(errs[0]), Equals, true)
+	c.Assert(errs[1], ErrorMatches, ".*no files match")
+}
+
+func (s *SourceSuite) Test_Walk(c *C) {
+	reader := NewReader[sourceRow]()
+	reader.HasHeader = true
+
+	records, errs := drain(reader.Walk(context.Background(), s.dir))
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].File < records[j].File
+	})
+
+	c.Assert(records, DeepEquals, []Record[sourceRow]{
+		{File: s.path("a.csv"), Row: sourceRow{Name: "a", Amount: 1}},
+		{File: s.path("nested/b.csv.gz"), Row: sourceRow{Name: "b", Amount: 2}},
+		{File: s.path("nested/c.csv.bz2"), Row: sourceRow{Name: "c", Amount: 3}},
+		{File: s.path("nested/d.csv.zst"), Row: sourceRow{Name: "d", Amount: 4}},
+	})
+
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, ".*d.csv.zst:3: column \"amount\".*")
+}
+
+func (s *SourceSuite) Test_Walk_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	reader := NewReader[sourceRow]()
+	reader.HasHeader = true
+
+	records, _ := drain(reader.Walk(ctx, s.dir))
+	c.Assert(records, HasLen, 0)
+}
+
+func (s *SourceSuite) Test_Walk_CancelUnread(c *C) {
+	// Every row of a large file is an error nobody reads
+	buf := &strings.Builder{}
+	buf.WriteString("name,amount\n")
+	for i := 0; i < 100000; i++ {
+		fmt.Fprintf(buf, "row%d,x\n", i)
+	}
+	c.Assert(os.WriteFile(s.path("big.csv"), []byte(buf.String()), 0o644), IsNil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	reader := NewReader[sourceRow]()
+	reader.HasHeader = true
+
+	outCh, errCh := reader.Walk(ctx, s.path("big.csv"), s.path("missing.csv"))
+	<-errCh
+	cancel()
+
+	// The walk must finish without errCh being drained
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for _ = range outCh {
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatal("walk blocked on an unread error channel")
+	}
+}
+
+func (s *SourceSuite) Test_ContextReader(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	reader := &contextReader{ctx: ctx, reader: strings.NewReader("abc")}
+
+	b := make([]byte, 1)
+	n, err := reader.Read(b)
+	c.Assert(n, Equals, 1)
+	c.Assert(err, IsNil)
+
+	cancel()
+	_, err = reader.Read(b)
+	c.Assert(err, Equals, context.Canceled)
+}
@@ -0,0 +1,293 @@
+package csv
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// ValidateTag struct tag holding the rules a field must satisfy
+	ValidateTag = "validate"
+)
+
+// ErrValidation matches every *Violation with errors.Is
+var ErrValidation = errors.New("validation failed")
+
+// Violation a value that breaks a validate rule. Rows with violations are
+// skipped and each violation is reported as the Err of a *ParseError.
+type Violation struct {
+	Rule  string
+	Param string
+}
+
+func (t *Violation) Error() string {
+	if t.Param == "" {
+		return "violates " + t.Rule
+	}
+	return "violates " + t.Rule + "=" + t.Param
+}
+
+func (t *Violation) Is(target error) bool {
+	return target == ErrValidation
+}
+
+// rule a compiled validate rule, check is nil for unique which depends on
+// the rows before
+type rule struct {
+	name  string
+	param string
+	check func(raw string, v reflect.Value) bool
+}
+
+// parseRules compile a validate tag for a field of type typ. Rules are
+// comma separated:
+//
+//	required      the column is present and not empty
+//	min=n, max=n  bounds of a number, or of the length of a string or slice
+//	enum=a|b|c    the value is one of those listed
+//	regex=expr    the value matches expr, as the rest of the tag it may
+//	              contain commas
+//	unique        no other row of the file has the same value
+//
+// Rules other than required pass empty values.
+func parseRules(tag string, typ reflect.Type) ([]rule, error) {
+	if tag == "" {
+		return nil, nil
+	}
+
+	var rules []rule
+	for tag != "" {
+		var part string
+		if strings.HasPrefix(tag, "regex=") {
+			part, tag = tag, ""
+		} else {
+			part, tag, _ = strings.Cut(tag, ",")
+		}
+
+		name, param, _ := strings.Cut(strings.TrimSpace(part), "=")
+		r := rule{name: name, param: param}
+
+		switch name {
+		case "required":
+			r.check = func(raw string, v reflect.Value) bool {
+				return strings.TrimSpace(raw) != ""
+			}
+
+		case "min", "max":
+			bound, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %s bound %q", name, param)
+			}
+			if _, ok := magnitude(reflect.Zero(typ)); !ok && !isNilable(typ) {
+				return nil, fmt.Errorf("%s is not supported for %v", name, typ)
+			}
+
+			isMin := name == "min"
+			r.check = func(raw string, v reflect.Value) bool {
+				n, ok := magnitude(v)
+				if !ok {
+					return true
+				}
+				if isMin {
+					return n >= bound
+				}
+				return n <= bound
+			}
+
+		case "enum":
+			if param == "" {
+				return nil, fmt.Errorf("enum has no values")
+			}
+			values := strings.Split(param, "|")
+			r.check = func(raw string, v reflect.Value) bool {
+				for _, value := range values {
+					if raw == value {
+						return true
+					}
+				}
+				return false
+			}
+
+		case "regex":
+			re, err := regexp.Compile(param)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex: %w", err)
+			}
+			r.check = func(raw string, v reflect.Value) bool {
+				return re.MatchString(raw)
+			}
+
+		case "unique":
+
+		default:
+			return nil, fmt.Errorf("unknown validate rule %q", name)
+		}
+
+		rules = append(rules, r)
+	}
+
+	return rules, nil
+}
+
+// isNilable pointers are checked against the type they point to
+func isNilable(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Pointer {
+		return false
+	}
+	_, ok := magnitude(reflect.Zero(typ.Elem()))
+	return ok
+}
+
+// magnitude the number min and max compare, false for a nil pointer or a
+// type they do not apply to
+func magnitude(v reflect.Value) (float64, bool) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return 0, false
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(v.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(v.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return v.Float(), true
+	case reflect.String:
+		return float64(utf8.RuneCountInString(v.String())), true
+	case reflect.Slice:
+		return float64(v.Len()), true
+	}
+	return 0, false
+}
+
+// checkRules the rules raw, decoded into v, violates. Unique is checked by
+// uniqueValues.
+func checkRules(rules []rule, raw string, v reflect.Value) []*Violation {
+	var violations []*Violation
+	for _, r := range rules {
+		if r.check == nil || (raw == "" && r.name != "required") {
+			continue
+		}
+		if !r.check(raw, v) {
+			violations = append(violations, &Violation{Rule: r.name, Param: r.param})
+		}
+	}
+	return violations
+}
+
+// hasRule whether rules include name
+func hasRule(rules []rule, name string) bool {
+	for _, r := range rules {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// uniqueValues values seen so far in each unique field of a file
+type uniqueValues map[int]map[any]struct{}
+
+// seen record the value of field, true if an earlier row had it. Empty
+// values and nil pointers are not recorded.
+func (t uniqueValues) seen(field int, raw string, v reflect.Value) bool {
+	if raw == "" {
+		return false
+	}
+
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+		v = v.Elem()
+	}
+
+	var key any = raw
+	if v.IsValid() && v.Comparable() {
+		key = v.Interface()
+	}
+
+	values, found := t[field]
+	if !found {
+		values = make(map[any]struct{})
+		t[field] = values
+	}
+
+	if _, found := values[key]; found {
+		return true
+	}
+	values[key] = struct{}{}
+	return false
+}
+
+// rulesError the first invalid validate tag of specs
+func rulesError(specs []fieldSpec) error {
+	for _, spec := range specs {
+		if spec.rulesErr != nil {
+			return spec.rulesErr
+		}
+	}
+	return nil
+}
+
+// validate the violations of obj decoded from row other than unique, as
+// ParseErrors without file or line
+func (t *rowDecoder[T]) validate(obj T, row []string) []*ParseError {
+	return t.violations(obj, row, func(i int, spec fieldSpec, raw string, field reflect.Value) []*Violation {
+		return checkRules(spec.rules, raw, field)
+	})
+}
+
+// validateUnique the unique violations of obj decoded from row, rows must be
+// checked in file order
+func (t *rowDecoder[T]) validateUnique(obj T, row []string, unique uniqueValues) []*ParseError {
+	return t.violations(obj, row, func(i int, spec fieldSpec, raw string, field reflect.Value) []*Violation {
+		if hasRule(spec.rules, "unique") && unique.seen(i, raw, field) {
+			return []*Violation{{Rule: "unique"}}
+		}
+		return nil
+	})
+}
+
+func (t *rowDecoder[T]) violations(obj T, row []string, check func(int, fieldSpec, string, reflect.Value) []*Violation) []*ParseError {
+	value := reflect.ValueOf(&obj).Elem()
+
+	var errs []*ParseError
+	for i, spec := range t.specs {
+		if len(spec.rules) == 0 {
+			continue
+		}
+
+		column := spec.column
+		if t.reader.HasHeader {
+			column = t.columns[i]
+		}
+
+		var raw string
+		if column >= 0 && column < len(row) {
+			raw = row[column]
+		}
+
+		for _, violation := range check(i, spec, raw, fieldByIndexRead(value, spec.index)) {
+			err := &ParseError{
+				Field:  spec.field,
+				Value:  raw,
+				Err:    violation,
+				column: column,
+			}
+			if column < 0 {
+				err.Column = spec.name
+			}
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
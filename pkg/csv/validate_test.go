@@ -0,0 +1,216 @@
+package csv
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	. "gopkg.in/check.v1"
+)
+
+type ValidateSuite struct{}
+
+var _ = Suite(&ValidateSuite{})
+
+type validRow struct {
+	ID     string   `csv:"id" validate:"required,unique"`
+	Amount int      `csv:"amount" validate:"min=1,max=100"`
+	Status string   `csv:"status" validate:"enum=open|closed"`
+	Code   string   `csv:"code" validate:"regex=^[A-Z]{2}[0-9]{1,3}$"`
+	Rate   *float64 `csv:"rate" validate:"max=1"`
+}
+
+const validRows = `id,amount,status,code,rate
+a,10,open,AB1,0.5
+b,0,open,AB2,
+,5,closed,CD3,
+c,500,pending,x,2
+a,20,closed,EF4,
+d,30,,,
+`
+
+func streamValid(c *C, reader *Reader[validRow], data string, parallel bool) ([]validRow, []error) {
+	reader.HasHeader = true
+
+	stream := reader.StreamFile
+	if parallel {
+		stream = reader.StreamParallel
+	}
+
+	outCh, errCh, err := stream("valid.csv", strings.NewReader(data))
+	c.Assert(err, IsNil)
+	return drain(outCh, errCh)
+}
+
+func (s *ValidateSuite) Test_Reader(c *C) {
+	rows, errs := streamValid(c, NewReader[validRow](), validRows, false)
+
+	c.Assert(rows, HasLen, 2)
+	c.Assert(rows[0].ID, Equals, "a")
+	c.Assert(rows[1].ID, Equals, "d")
+
+	c.Assert(errorStrings(errs), DeepEquals, []string{
+		`valid.csv:3: column "amount" field Amount value "0": violates min=1`,
+		`valid.csv:4: column "id" field ID value "": violates required`,
+		`valid.csv:5: column "amount" field Amount value "500": violates max=100`,
+		`valid.csv:5: column "status" field Status value "pending": violates enum=open|closed`,
+		`valid.csv:5: column "code" field Code value "x": violates regex=^[A-Z]{2}[0-9]{1,3}$`,
+		`valid.csv:5: column "rate" field Rate value "2": violates max=1`,
+		`valid.csv:6: column "id" field ID value "a": violates unique`,
+	})
+
+	var parseErr *ParseError
+	c.Assert(errors.As(errs[0], &parseErr), Equals, true)
+	c.Assert(parseErr.Line, Equals, 3)
+	c.Assert(parseErr.Column, Equals, "amount")
+
+	var violation *Violation
+	c.Assert(errors.As(errs[0], &violation), Equals, true)
+	c.Assert(violation.Rule, Equals, "min")
+	c.Assert(violation.Param, Equals, "1")
+	c.Assert(errors.Is(errs[0], ErrValidation), Equals, true)
+}
+
+func (s *ValidateSuite) Test_Reader_FailFast(c *C) {
+	reader := NewReader[validRow]()
+	reader.ErrorPolicy = FailFast
+
+	rows, errs := streamValid(c, reader, validRows, false)
+	c.Assert(rows, HasLen, 1)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errors.Is(errs[0], ErrValidation), Equals, true)
+}
+
+func (s *ValidateSuite) Test_Reader_ParseErrorFirst(c *C) {
+	rows, errs := streamValid(c, NewReader[validRow](), "id,amount,status,code,rate\na,x,bad,,\n", false)
+	c.Assert(rows, HasLen, 0)
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errors.Is(errs[0], ErrValidation), Equals, false)
+}
+
+func (s *ValidateSuite) Test_Reader_Unique_Parallel(c *C) {
+	buf := &strings.Builder{}
+	buf.WriteString("id,amount,status,code,rate\n")
+	for i := 0; i < 500; i++ {
+		fmt.Fprintf(buf, "id%d,1,open,AB1,\n", i%100)
+	}
+
+	reader := NewReader[validRow]()
+	reader.Workers = 4
+	reader.ChunkSize = 256
+	reader.Ordered = true
+
+	rows, errs := streamValid(c, reader, buf.String(), true)
+	c.Assert(rows, HasLen, 100)
+	c.Assert(errs, HasLen, 400)
+	for i, row := range rows {
+		c.Assert(row.ID, Equals, fmt.Sprintf("id%d", i))
+	}
+	c.Assert(errs[0], ErrorMatches, `valid.csv:102: column "id" field ID value "id0": violates unique`)
+
+	// Unordered keeps one row of each value, not necessarily the first
+	reader.Ordered = false
+	rows, errs = streamValid(c, reader, buf.String(), true)
+	c.Assert(rows, HasLen, 100)
+	c.Assert(errs, HasLen, 400)
+}
+
+func (s *ValidateSuite) Test_Reader_Parallel(c *C) {
+	reader := NewReader[validRow]()
+	reader.ChunkSize = 16
+	reader.Ordered = true
+
+	rows, errs := streamValid(c, reader, validRows, true)
+	expected, expectedErrs := streamValid(c, NewReader[validRow](), validRows, false)
+	c.Assert(rows, DeepEquals, expected)
+	c.Assert(errorStrings(errs), DeepEquals, errorStrings(expectedErrs))
+}
+
+func (s *ValidateSuite) Test_Reader_InvalidTag(c *C) {
+	type badRegex struct {
+		Code string `csv:"code" validate:"regex=[a-"`
+	}
+	_, _, err := NewReader[badRegex]().StreamFile("", strings.NewReader(""))
+	c.Assert(err, ErrorMatches, "field Code: invalid regex: .*")
+
+	type badType struct {
+		Open bool `csv:"open" validate:"min=1"`
+	}
+	_, _, err = NewReader[badType]().StreamParallel("", strings.NewReader(""))
+	c.Assert(err, ErrorMatches, "field Open: min is not supported for bool")
+
+	type badRule struct {
+		Name string `csv:"name" validate:"email"`
+	}
+	_, _, err = NewReader[badRule]().StreamFile("", strings.NewReader(""))
+	c.Assert(err, ErrorMatches, `field Name: unknown validate rule "email"`)
+
+	type badBound struct {
+		Name string `csv:"name" validate:"max=ten"`
+	}
+	_, _, err = NewReader[badBound]().StreamFile("", strings.NewReader(""))
+	c.Assert(err, ErrorMatches, `field Name: invalid max bound "ten"`)
+}
+
+func (s *ValidateSuite) Test_ParseRules(c *C) {
+	rules, err := parseRules("required, min=2,regex=^a,b$", reflect.TypeOf(""))
+	c.Assert(err, IsNil)
+	c.Assert(rules, HasLen, 3)
+	c.Assert(rules[1].name, Equals, "min")
+	c.Assert(rules[2].param, Equals, "^a,b$")
+
+	c.Assert(checkRules(rules, "a,b", reflect.ValueOf("a,b")), HasLen, 0)
+	c.Assert(checkRules(rules, "a", reflect.ValueOf("a")), HasLen, 2)
+	c.Assert(checkRules(rules, "", reflect.ValueOf("")), DeepEquals, []*Violation{{Rule: "required"}})
+}
+
+type decodeValidRow struct {
+	ID     string `validate:"required,unique"`
+	Amount int    `validate:"min=1"`
+}
+
+func (s *ValidateSuite) Test_Decoder(c *C) {
+	const data = `id,amount
+a,1
+b,0
+,2
+a,3
+c,4
+`
+	var rows []decodeValidRow
+	decoder := NewDecoder(csv.NewReader(strings.NewReader(data)))
+	decoder.HasHeader()
+	decoder.SetFile("valid.csv")
+
+	err := decoder.Decode(&rows)
+	c.Assert(err, ErrorMatches, `valid.csv:3: column "amount" field Amount value "0": violates min=1`)
+	c.Assert(errors.Is(err, ErrValidation), Equals, true)
+	c.Assert(rows, HasLen, 1)
+
+	rows = nil
+	decoder = NewDecoder(csv.NewReader(strings.NewReader(data)))
+	decoder.HasHeader()
+	decoder.SetErrorPolicy(SkipAndReport, 0)
+
+	err = decoder.Decode(&rows)
+	c.Assert(rows, DeepEquals, []decodeValidRow{{"a", 1}, {"c", 4}})
+
+	var errs ParseErrors
+	c.Assert(errors.As(err, &errs), Equals, true)
+	c.Assert(errs, HasLen, 3)
+	c.Assert(errs[1].Err, DeepEquals, &Violation{Rule: "required"})
+	c.Assert(errs[2], ErrorMatches, `5: column "id" field ID value "a": violates unique`)
+}
+
+func (s *ValidateSuite) Test_Decoder_InvalidTag(c *C) {
+	type badRow struct {
+		Name string `validate:"enum"`
+		Open bool   `validate:"max=1"`
+	}
+
+	var rows []badRow
+	err := NewDecoder(csv.NewReader(strings.NewReader("a,true\n"))).Decode(&rows)
+	c.Assert(err, ErrorMatches, "field Name: enum has no values")
+}
@@ -0,0 +1,218 @@
+package csv
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/sjhitchner/toolbox/pkg/streaming"
+)
+
+// Writer writes structs as CSV rows using the same csv tags as Reader.
+// Numeric tags give the column index of a field, name tags give its header,
+// fields without a tag are written in field order under their field name.
+// Fields tagged "-" are skipped. Tag options, embedded structs and field
+// types are handled as they are by Reader, types may implement CSVMarshaler
+// to format themselves.
+type Writer[T any] struct {
+	Comma      rune
+	DateFormat string
+	HasHeader  bool
+
+	writer  io.Writer
+	csv     *csv.Writer
+	columns []writeColumn
+	started bool
+}
+
+type writeColumn struct {
+	name string
+	spec *fieldSpec
+}
+
+// NewWriter create a writer of T to w, a header is written by default
+func NewWriter[T any](w io.Writer) *Writer[T] {
+	return &Writer[T]{
+		Comma:      Comma,
+		DateFormat: defaultDateFormat,
+		HasHeader:  true,
+		writer:     w,
+	}
+}
+
+// start build the column layout and write the header, settings are fixed
+// from the first write
+func (t *Writer[T]) start() error {
+	if t.started {
+		return nil
+	}
+
+	var obj T
+	typ := reflect.TypeOf(obj)
+	if typ == nil || typ.Kind() != reflect.Struct {
+		return fmt.Errorf("Type T is not a struct")
+	}
+
+	columns, err := writeColumns(typ)
+	if err != nil {
+		return err
+	}
+
+	t.columns = columns
+	t.csv = csv.NewWriter(t.writer)
+	t.csv.Comma = t.Comma
+	t.started = true
+
+	if t.HasHeader {
+		if err := t.csv.Write(t.Header()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeColumns column layout of typ, ordered by column index
+func writeColumns(typ reflect.Type) ([]writeColumn, error) {
+	specs := fieldSpecs(typ)
+
+	var numeric int
+	for i := range specs {
+		if specs[i].column >= 0 {
+			numeric++
+		} else {
+			specs[i].column = i
+		}
+	}
+
+	if numeric > 0 && numeric != len(specs) {
+		return nil, fmt.Errorf("csv tags must be all column numbers or none, %d of %d fields are numbered", numeric, len(specs))
+	}
+
+	width := 0
+	for _, spec := range specs {
+		if spec.column+1 > width {
+			width = spec.column + 1
+		}
+	}
+
+	// Numbered columns may leave gaps, they are written empty
+	out := make([]writeColumn, width)
+	for _, spec := range specs {
+		if out[spec.column].spec != nil {
+			return nil, fmt.Errorf("csv column %d is used by more than one field", spec.column)
+		}
+
+		spec := spec
+		out[spec.column] = writeColumn{
+			name: spec.name,
+			spec: &spec,
+		}
+	}
+	return out, nil
+}
+
+// Header column names in the order they are written
+func (t *Writer[T]) Header() []string {
+	header := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		header[i] = column.name
+	}
+	return header
+}
+
+// Write obj as a row, rows are buffered until Flush
+func (t *Writer[T]) Write(obj T) error {
+	if err := t.start(); err != nil {
+		return err
+	}
+
+	row, err := t.format(obj)
+	if err != nil {
+		return err
+	}
+	return t.csv.Write(row)
+}
+
+func (t *Writer[T]) format(obj T) ([]string, error) {
+	// Addressable so pointer receiver marshalers are found
+	value := reflect.New(reflect.TypeOf(obj)).Elem()
+	value.Set(reflect.ValueOf(obj))
+
+	row := make([]string, len(t.columns))
+	for i, column := range t.columns {
+		if column.spec == nil {
+			continue
+		}
+
+		format := column.spec.format
+		if format == "" {
+			format = t.DateFormat
+		}
+
+		str, err := formatValue(fieldByIndexRead(value, column.spec.index), format, column.spec.sep)
+		if err != nil {
+			return nil, fmt.Errorf("column %s: %w", column.name, err)
+		}
+		row[i] = str
+	}
+	return row, nil
+}
+
+// WriteAll write every row then flush
+func (t *Writer[T]) WriteAll(rows []T) error {
+	for _, row := range rows {
+		if err := t.Write(row); err != nil {
+			return err
+		}
+	}
+	return t.Flush()
+}
+
+// Flush write any buffered rows to the underlying writer, the header is
+// written even if there were no rows
+func (t *Writer[T]) Flush() error {
+	if err := t.start(); err != nil {
+		return err
+	}
+
+	t.csv.Flush()
+	return t.csv.Error()
+}
+
+// Sink write every row received from in, flushing once in is closed. Rows
+// that cannot be formatted are reported and skipped, a write error stops the
+// sink and the remainder of in is discarded.
+func (t *Writer[T]) Sink(in <-chan T) <-chan error {
+	errCh := make(chan error)
+
+	go func() {
+		defer close(errCh)
+
+		if err := t.start(); err != nil {
+			errCh <- err
+			streaming.Consume(in)
+			return
+		}
+
+		for obj := range in {
+			row, err := t.format(obj)
+			if err != nil {
+				errCh <- err
+				continue
+			}
+
+			if err := t.csv.Write(row); err != nil {
+				errCh <- err
+				streaming.Consume(in)
+				return
+			}
+		}
+
+		if err := t.Flush(); err != nil {
+			errCh <- err
+		}
+	}()
+
+	return errCh
+}
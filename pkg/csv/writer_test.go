@@ -0,0 +1,158 @@
+package csv
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type WriterSuite struct{}
+
+var _ = Suite(&WriterSuite{})
+
+type writeRow struct {
+	Name    string    `csv:"name"`
+	Count   int       `csv:"count"`
+	Price   float64   `csv:"price"`
+	Active  bool      `csv:"active"`
+	Date    time.Time `csv:"date"`
+	Skipped string    `csv:"-"`
+	private string
+}
+
+func (s *WriterSuite) Test_Write(c *C) {
+	buf := &bytes.Buffer{}
+
+	w := NewWriter[writeRow](buf)
+	w.DateFormat = "2006-01-02"
+
+	c.Assert(w.WriteAll([]writeRow{
+		{"a", 1, 1.5, true, time.Date(2021, 1, 2, 0, 0, 0, 0, time.UTC), "x", "y"},
+		{"b,c", 2, 0, false, time.Date(2021, 3, 4, 0, 0, 0, 0, time.UTC), "x", "y"},
+	}), IsNil)
+
+	c.Assert(buf.String(), Equals, `name,count,price,active,date
+a,1,1.5,true,2021-01-02
+"b,c",2,0,false,2021-03-04
+`)
+}
+
+// label formats itself with a value receiver
+type label string
+
+func (t label) MarshalCSV() (string, error) {
+	return "<" + string(t) + ">", nil
+}
+
+func (s *WriterSuite) Test_Write_NilMarshaler(c *C) {
+	buf := &bytes.Buffer{}
+
+	type labelRow struct {
+		Name  string `csv:"name"`
+		Label *label `csv:"label"`
+	}
+
+	value := label("x")
+	c.Assert(NewWriter[labelRow](buf).WriteAll([]labelRow{
+		{Name: "a", Label: &value},
+		{Name: "b"},
+	}), IsNil)
+
+	c.Assert(buf.String(), Equals, "name,label\na,<x>\nb,\n")
+}
+
+func (s *WriterSuite) Test_Write_NumTag(c *C) {
+	buf := &bytes.Buffer{}
+
+	type numRow struct {
+		B string `csv:"2"`
+		A string `csv:"0"`
+	}
+
+	w := NewWriter[numRow](buf)
+	w.HasHeader = false
+	w.Comma = Tab
+
+	c.Assert(w.WriteAll([]numRow{{B: "b", A: "a"}}), IsNil)
+	c.Assert(buf.String(), Equals, "a\t\tb\n")
+}
+
+func (s *WriterSuite) Test_Write_MixedTags(c *C) {
+	type mixedRow struct {
+		A string `csv:"0"`
+		B string `csv:"b"`
+	}
+
+	err := NewWriter[mixedRow](&bytes.Buffer{}).Flush()
+	c.Assert(err, ErrorMatches, "csv tags must be all column numbers or none.*")
+}
+
+func (s *WriterSuite) Test_RoundTrip(c *C) {
+	buf := &bytes.Buffer{}
+	c.Assert(NewWriter[ColNameTag](buf).WriteAll([]ColNameTag{
+		{"foo1", "qwerty1", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{"foo2", "qwerty2", time.Date(2021, 2, 2, 0, 0, 0, 0, time.UTC)},
+	}), IsNil)
+
+	reader := NewReader[ColNameTag]()
+	reader.HasHeader = true
+
+	outCh, errCh, err := reader.Stream(strings.NewReader(buf.String()))
+	c.Assert(err, IsNil)
+	assertNoError(c, errCh)
+
+	row0 := <-outCh
+	c.Assert(row0.Foo, Equals, "foo1")
+	c.Assert(row0.Date.Equal(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)), Equals, true)
+
+	row1 := <-outCh
+	c.Assert(row1.Qwerty, Equals, "qwerty2")
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errors.New("disk full")
+}
+
+func (s *WriterSuite) Test_Sink(c *C) {
+	buf := &bytes.Buffer{}
+
+	in := make(chan ColNumTag)
+	errCh := NewWriter[ColNumTag](buf).Sink(in)
+
+	go func() {
+		defer close(in)
+		in <- ColNumTag{"foo1", "qwerty1", time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)}
+		in <- ColNumTag{"foo2", "qwerty2", time.Date(2021, 2, 2, 0, 0, 0, 0, time.UTC)}
+	}()
+
+	for err := range errCh {
+		c.Assert(err, IsNil)
+	}
+
+	c.Assert(buf.String(), Equals, `Foo,Qwerty,Date
+foo1,qwerty1,2021-01-01T00:00:00Z
+foo2,qwerty2,2021-02-02T00:00:00Z
+`)
+}
+
+func (s *WriterSuite) Test_Sink_Error(c *C) {
+	in := make(chan ColNumTag)
+	errCh := NewWriter[ColNumTag](failWriter{}).Sink(in)
+
+	go func() {
+		defer close(in)
+		in <- ColNumTag{Foo: "foo1"}
+	}()
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, "disk full")
+}
@@ -0,0 +1,213 @@
+package set
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+)
+
+// Bag multiset counting how many times each element was added
+type Bag[T comparable] map[T]int
+
+// Counted element of a bag with its count
+type Counted[T comparable] struct {
+	Value T
+	Count int
+}
+
+// NewBag create new bag
+func NewBag[T comparable](s ...T) Bag[T] {
+	bag := make(Bag[T])
+	bag.Add(s...)
+	return bag
+}
+
+// Add one of each element
+func (bag Bag[T]) Add(s ...T) {
+	for _, v := range s {
+		bag[v]++
+	}
+}
+
+// AddN add n of v
+func (bag Bag[T]) AddN(v T, n int) {
+	if n <= 0 {
+		return
+	}
+	bag[v] += n
+}
+
+// Remove one of v
+func (bag Bag[T]) Remove(v T) {
+	bag.RemoveN(v, 1)
+}
+
+// RemoveN remove up to n of v
+func (bag Bag[T]) RemoveN(v T, n int) {
+	if n <= 0 {
+		return
+	}
+	if bag[v] <= n {
+		delete(bag, v)
+		return
+	}
+	bag[v] -= n
+}
+
+// RemoveAll remove every v
+func (bag Bag[T]) RemoveAll(v T) {
+	delete(bag, v)
+}
+
+// Count number of v in the bag
+func (bag Bag[T]) Count(v T) int {
+	return bag[v]
+}
+
+// Contains is every value in the bag at least once
+func (bag Bag[T]) Contains(s ...T) bool {
+	for _, v := range s {
+		if bag[v] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// Len total number of elements, counting duplicates
+func (bag Bag[T]) Len() int {
+	var n int
+	for _, count := range bag {
+		n += count
+	}
+	return n
+}
+
+// Cardinality number of distinct elements
+func (bag Bag[T]) Cardinality() int {
+	return len(bag)
+}
+
+func (bag Bag[T]) IsEmpty() bool {
+	return len(bag) == 0
+}
+
+// MostCommon the n elements with the highest counts, highest first. n <= 0
+// returns every element.
+func (bag Bag[T]) MostCommon(n int) []Counted[T] {
+	type keyed struct {
+		Counted[T]
+		key string
+	}
+
+	// Ties are broken on the printed value so the order is stable
+	sorted := make([]keyed, 0, len(bag))
+	for v, count := range bag {
+		sorted = append(sorted, keyed{Counted[T]{Value: v, Count: count}, fmt.Sprint(v)})
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].Count != sorted[j].Count {
+			return sorted[i].Count > sorted[j].Count
+		}
+		return sorted[i].key < sorted[j].key
+	})
+
+	counts := make([]Counted[T], len(sorted))
+	for i, k := range sorted {
+		counts[i] = k.Counted
+	}
+
+	if n > 0 && n < len(counts) {
+		counts = counts[:n]
+	}
+	return counts
+}
+
+// Union multiset union, the count of each element is the larger of the two
+func (bag Bag[T]) Union(other Bag[T]) Bag[T] {
+	union := bag.Clone()
+	for v, count := range other {
+		if count > union[v] {
+			union[v] = count
+		}
+	}
+	return union
+}
+
+// Intersect multiset intersection, the count of each element is the smaller
+// of the two
+func (bag Bag[T]) Intersect(other Bag[T]) Bag[T] {
+	intersection := NewBag[T]()
+	for v, count := range bag {
+		if o := other[v]; o > 0 {
+			intersection[v] = min(count, o)
+		}
+	}
+	return intersection
+}
+
+// Sum add the counts of both bags
+func (bag Bag[T]) Sum(other Bag[T]) Bag[T] {
+	sum := bag.Clone()
+	for v, count := range other {
+		sum[v] += count
+	}
+	return sum
+}
+
+// Difference subtract the counts of other, dropping elements that reach 0
+func (bag Bag[T]) Difference(other Bag[T]) Bag[T] {
+	difference := NewBag[T]()
+	for v, count := range bag {
+		if count > other[v] {
+			difference[v] = count - other[v]
+		}
+	}
+	return difference
+}
+
+func (bag Bag[T]) Equals(other Bag[T]) bool {
+	if len(bag) != len(other) {
+		return false
+	}
+
+	for v, count := range bag {
+		if other[v] != count {
+			return false
+		}
+	}
+	return true
+}
+
+func (bag Bag[T]) Clone() Bag[T] {
+	clone := make(Bag[T], len(bag))
+	for v, count := range bag {
+		clone[v] = count
+	}
+	return clone
+}
+
+// ToSet distinct elements of the bag
+func (bag Bag[T]) ToSet() Set[T] {
+	set := make(Set[T], len(bag))
+	for v := range bag {
+		set.Add(v)
+	}
+	return set
+}
+
+func (bag Bag[T]) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprint(buf, "Bag{")
+
+	for i, c := range bag.MostCommon(0) {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(buf, "%v: %d", c.Value, c.Count)
+	}
+
+	buf.WriteRune('}')
+	return buf.String()
+}
@@ -0,0 +1,60 @@
+package set
+
+import (
+	. "github.com/sjhitchner/toolbox/pkg/testing"
+	. "gopkg.in/check.v1"
+)
+
+type BagSuite struct{}
+
+var _ = Suite(&BagSuite{})
+
+func (s *BagSuite) Test_Add(c *C) {
+	b := NewBag("a", "b", "a")
+	b.AddN("c", 3)
+
+	c.Assert(b.Count("a"), Equals, 2)
+	c.Assert(b.Count("c"), Equals, 3)
+	c.Assert(b.Count("z"), Equals, 0)
+	c.Assert(b.Len(), Equals, 6)
+	c.Assert(b.Cardinality(), Equals, 3)
+	c.Assert(b.Contains("a", "b"), IsTrue)
+
+	b.Remove("a")
+	c.Assert(b.Count("a"), Equals, 1)
+	b.RemoveN("c", 5)
+	c.Assert(b.Contains("c"), IsFalse)
+	b.RemoveAll("a")
+	c.Assert(b.ToSet().Equals(New("b")), IsTrue)
+
+	// Non positive counts are ignored
+	b.RemoveN("b", -2)
+	b.RemoveN("z", -1)
+	b.RemoveN("b", 0)
+	c.Assert(b.Count("b"), Equals, 1)
+	c.Assert(b.Contains("z"), IsFalse)
+	c.Assert(b.Cardinality(), Equals, 1)
+}
+
+func (s *BagSuite) Test_MostCommon(c *C) {
+	b := NewBag("x", "y", "y", "z", "z", "z")
+
+	top := b.MostCommon(2)
+	c.Assert(top, DeepEquals, []Counted[string]{{"z", 3}, {"y", 2}})
+	c.Assert(b.MostCommon(0), HasLen, 3)
+	c.Assert(b.String(), Equals, "Bag{z: 3, y: 2, x: 1}")
+
+	ties := NewBag(3, 1, 2, 10)
+	c.Assert(ties.MostCommon(0), DeepEquals, []Counted[int]{{1, 1}, {10, 1}, {2, 1}, {3, 1}})
+}
+
+func (s *BagSuite) Test_Operations(c *C) {
+	a := NewBag(1, 1, 2)
+	b := NewBag(1, 2, 2, 3)
+
+	c.Assert(a.Union(b).Equals(Bag[int]{1: 2, 2: 2, 3: 1}), IsTrue)
+	c.Assert(a.Intersect(b).Equals(Bag[int]{1: 1, 2: 1}), IsTrue)
+	c.Assert(a.Sum(b).Equals(Bag[int]{1: 3, 2: 3, 3: 1}), IsTrue)
+	c.Assert(a.Difference(b).Equals(Bag[int]{1: 1}), IsTrue)
+	c.Assert(a.Equals(b), IsFalse)
+}
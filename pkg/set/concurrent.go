@@ -0,0 +1,260 @@
+package set
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+const (
+	DefaultShards = 32
+)
+
+// ConcurrentSet set safe for use by multiple goroutines. Elements are spread
+// over shards each with its own lock so writers to different shards do not
+// contend. Iteration and the operations combining sets work on a snapshot
+// taken with every shard locked, so they see the set at a single point in
+// time. The zero value is an empty set with DefaultShards shards.
+type ConcurrentSet[T comparable] struct {
+	once   sync.Once
+	shards []*shard[T]
+}
+
+type shard[T comparable] struct {
+	mu    sync.RWMutex
+	items Set[T]
+}
+
+// NewConcurrent create new concurrent set with DefaultShards shards
+func NewConcurrent[T comparable](s ...T) *ConcurrentSet[T] {
+	return NewConcurrentShards[T](DefaultShards, s...)
+}
+
+// NewConcurrentShards create new concurrent set with n shards
+func NewConcurrentShards[T comparable](n int, s ...T) *ConcurrentSet[T] {
+	if n < 1 {
+		n = 1
+	}
+
+	set := &ConcurrentSet[T]{
+		shards: newShards[T](n),
+	}
+	set.Add(s...)
+	return set
+}
+
+func newShards[T comparable](n int) []*shard[T] {
+	shards := make([]*shard[T], n)
+	for i := range shards {
+		shards[i] = &shard[T]{items: New[T]()}
+	}
+	return shards
+}
+
+// getShards shards of the set, created on first use for a zero value set
+func (set *ConcurrentSet[T]) getShards() []*shard[T] {
+	set.once.Do(func() {
+		if set.shards == nil {
+			set.shards = newShards[T](DefaultShards)
+		}
+	})
+	return set.shards
+}
+
+func (set *ConcurrentSet[T]) shard(v T) *shard[T] {
+	shards := set.getShards()
+	if len(shards) == 1 {
+		return shards[0]
+	}
+	return shards[Hash(v)%uint64(len(shards))]
+}
+
+// lockAll read lock every shard in order, returns the unlock function
+func (set *ConcurrentSet[T]) lockAll() func() {
+	shards := set.getShards()
+	for _, s := range shards {
+		s.mu.RLock()
+	}
+
+	return func() {
+		for _, s := range shards {
+			s.mu.RUnlock()
+		}
+	}
+}
+
+// Snapshot copy of the set at a single point in time
+func (set *ConcurrentSet[T]) Snapshot() Set[T] {
+	unlock := set.lockAll()
+	defer unlock()
+
+	var size int
+	for _, s := range set.shards {
+		size += len(s.items)
+	}
+
+	snapshot := make(Set[T], size)
+	for _, s := range set.shards {
+		for elem := range s.items {
+			snapshot[elem] = struct{}{}
+		}
+	}
+	return snapshot
+}
+
+// Add to set
+func (set *ConcurrentSet[T]) Add(s ...T) {
+	for _, v := range s {
+		set.CheckAndAdd(v)
+	}
+}
+
+// CheckAndAdd atomically check if exists and add, true if v was added
+func (set *ConcurrentSet[T]) CheckAndAdd(v T) bool {
+	s := set.shard(v)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.items.CheckAndAdd(v)
+}
+
+// Merge Sets
+func (set *ConcurrentSet[T]) Merge(others ...*ConcurrentSet[T]) {
+	for _, other := range others {
+		for elem := range other.Snapshot() {
+			set.Add(elem)
+		}
+	}
+}
+
+// Contains does value exist in set
+func (set *ConcurrentSet[T]) Contains(i ...T) bool {
+	for _, v := range i {
+		s := set.shard(v)
+		s.mu.RLock()
+		_, ok := s.items[v]
+		s.mu.RUnlock()
+
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *ConcurrentSet[T]) IsSubset(other *ConcurrentSet[T]) bool {
+	return set.Snapshot().IsSubset(other.Snapshot())
+}
+
+func (set *ConcurrentSet[T]) IsSuperset(other *ConcurrentSet[T]) bool {
+	return other.IsSubset(set)
+}
+
+func (set *ConcurrentSet[T]) Union(other ...*ConcurrentSet[T]) *ConcurrentSet[T] {
+	union := set.Clone()
+	union.Merge(other...)
+	return union
+}
+
+func (set *ConcurrentSet[T]) Intersect(other *ConcurrentSet[T]) *ConcurrentSet[T] {
+	return set.fromSet(set.Snapshot().Intersect(other.Snapshot()))
+}
+
+func (set *ConcurrentSet[T]) Difference(other *ConcurrentSet[T]) *ConcurrentSet[T] {
+	return set.fromSet(set.Snapshot().Difference(other.Snapshot()))
+}
+
+func (set *ConcurrentSet[T]) SymmetricDifference(other *ConcurrentSet[T]) *ConcurrentSet[T] {
+	return set.fromSet(set.Snapshot().SymmetricDifference(other.Snapshot()))
+}
+
+func (set *ConcurrentSet[T]) HasOverlap(other *ConcurrentSet[T]) bool {
+	return set.Snapshot().HasOverlap(other.Snapshot())
+}
+
+// fromSet new concurrent set with the same number of shards as set
+func (set *ConcurrentSet[T]) fromSet(other Set[T]) *ConcurrentSet[T] {
+	out := NewConcurrentShards[T](len(set.getShards()))
+	for elem := range other {
+		out.shard(elem).items.Add(elem)
+	}
+	return out
+}
+
+func (set *ConcurrentSet[T]) Clear() {
+	for _, s := range set.getShards() {
+		s.mu.Lock()
+		s.items = New[T]()
+		s.mu.Unlock()
+	}
+}
+
+func (set *ConcurrentSet[T]) Remove(i T) {
+	s := set.shard(i)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.items.Remove(i)
+}
+
+func (set *ConcurrentSet[T]) Cardinality() int {
+	unlock := set.lockAll()
+	defer unlock()
+
+	var n int
+	for _, s := range set.shards {
+		n += len(s.items)
+	}
+	return n
+}
+
+func (set *ConcurrentSet[T]) IsEmpty() bool {
+	return set.Cardinality() == 0
+}
+
+// Iter iterate over a snapshot of the set
+func (set *ConcurrentSet[T]) Iter() <-chan T {
+	return set.Snapshot().Iter()
+}
+
+// Iterator iterate over a snapshot of the set
+func (set *ConcurrentSet[T]) Iterator() *Iterator[T] {
+	return set.Snapshot().Iterator()
+}
+
+func (set *ConcurrentSet[T]) Equals(other *ConcurrentSet[T]) bool {
+	return set.Snapshot().Equals(other.Snapshot())
+}
+
+func (set *ConcurrentSet[T]) Clone() *ConcurrentSet[T] {
+	return set.fromSet(set.Snapshot())
+}
+
+func (set *ConcurrentSet[T]) String() string {
+	return set.Snapshot().String()
+}
+
+func (set *ConcurrentSet[T]) ToSlice() []T {
+	return set.Snapshot().ToSlice()
+}
+
+// MarshalJSON creates a JSON array from a snapshot of the set
+func (set *ConcurrentSet[T]) MarshalJSON() ([]byte, error) {
+	return set.Snapshot().MarshalJSON()
+}
+
+// UnmarshalJSON replaces the contents of the set with a JSON array
+func (set *ConcurrentSet[T]) UnmarshalJSON(b []byte) error {
+	var list []T
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+
+	set.replace(list)
+	return nil
+}
+
+// replace the contents of the set with list
+func (set *ConcurrentSet[T]) replace(list []T) {
+	set.Clear()
+	set.Add(list...)
+}
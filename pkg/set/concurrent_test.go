@@ -0,0 +1,155 @@
+package set
+
+import (
+	"encoding/json"
+	"math"
+	"sync"
+
+	. "gopkg.in/check.v1"
+)
+
+type ConcurrentSuite struct{}
+
+var _ = Suite(&ConcurrentSuite{})
+
+func (s *ConcurrentSuite) Test_Add(c *C) {
+	s1 := NewConcurrent("test1", "test2")
+	s1.Add("test3")
+	c.Assert(s1.CheckAndAdd("test3"), Equals, false)
+	c.Assert(s1.CheckAndAdd("test4"), Equals, true)
+
+	c.Assert(s1.Cardinality(), Equals, 4)
+	c.Assert(s1.Contains("test1", "test4"), Equals, true)
+	c.Assert(s1.Contains("test5"), Equals, false)
+
+	s1.Remove("test1")
+	c.Assert(s1.Contains("test1"), Equals, false)
+
+	s1.Clear()
+	c.Assert(s1.IsEmpty(), Equals, true)
+}
+
+func (s *ConcurrentSuite) Test_CheckAndAdd_Concurrent(c *C) {
+	s1 := NewConcurrentShards[int](4)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var added int
+
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				if s1.CheckAndAdd(i) {
+					mu.Lock()
+					added++
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every element is added exactly once across all goroutines
+	c.Assert(added, Equals, 1000)
+	c.Assert(s1.Cardinality(), Equals, 1000)
+}
+
+func (s *ConcurrentSuite) Test_Snapshot(c *C) {
+	s1 := NewConcurrent(1, 2, 3)
+
+	snapshot := s1.Snapshot()
+	s1.Add(4)
+
+	c.Assert(snapshot.Equals(New(1, 2, 3)), Equals, true)
+
+	var count int
+	for _ = range s1.Iter() {
+		count++
+	}
+	c.Assert(count, Equals, 4)
+}
+
+func (s *ConcurrentSuite) Test_Operations(c *C) {
+	a := NewConcurrent(1, 2, 3, 4)
+	b := NewConcurrent(3, 4, 5)
+
+	c.Assert(a.Union(b).Equals(NewConcurrent(1, 2, 3, 4, 5)), Equals, true)
+	c.Assert(a.Intersect(b).Equals(NewConcurrent(3, 4)), Equals, true)
+	c.Assert(a.Difference(b).Equals(NewConcurrent(1, 2)), Equals, true)
+	c.Assert(a.SymmetricDifference(b).Equals(NewConcurrent(1, 2, 5)), Equals, true)
+	c.Assert(a.HasOverlap(b), Equals, true)
+	c.Assert(NewConcurrent(3).IsSubset(a), Equals, true)
+	c.Assert(a.IsSuperset(b), Equals, false)
+
+	clone := a.Clone()
+	clone.Add(10)
+	c.Assert(a.Contains(10), Equals, false)
+}
+
+func (s *ConcurrentSuite) Test_JSON(c *C) {
+	var s1 ConcurrentSet[int]
+	c.Assert(json.Unmarshal([]byte("[1,2,2]"), &s1), IsNil)
+	c.Assert(s1.Cardinality(), Equals, 2)
+	c.Assert(s1.Contains(1, 2), Equals, true)
+}
+
+func (s *ConcurrentSuite) Test_ZeroValue(c *C) {
+	var s1 ConcurrentSet[int]
+	c.Assert(s1.IsEmpty(), Equals, true)
+	c.Assert(s1.Contains(1), Equals, false)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s1.Add(i, i+1)
+		}(i)
+	}
+	wg.Wait()
+
+	c.Assert(s1.Cardinality(), Equals, 9)
+	c.Assert(s1.Clone().Equals(&s1), Equals, true)
+}
+
+func (s *ConcurrentSuite) Test_SignedZero(c *C) {
+	negZero := math.Copysign(0, -1)
+
+	s1 := NewConcurrent(0.0, negZero)
+	c.Assert(s1.Cardinality(), Equals, New(0.0, negZero).Cardinality())
+	c.Assert(s1.Cardinality(), Equals, 1)
+	c.Assert(s1.Contains(negZero), Equals, true)
+}
+
+func (s *ConcurrentSuite) Test_Hash(c *C) {
+	type key struct {
+		name string
+		n    int
+	}
+
+	c.Assert(Hash("abc"), Equals, Hash("abc"))
+	c.Assert(Hash(10), Equals, Hash(10))
+	c.Assert(Hash(10) != Hash(11), Equals, true)
+	c.Assert(Hash(1.5) != Hash(2.5), Equals, true)
+	c.Assert(Hash(key{"a", 1}), Equals, Hash(key{"a", 1}))
+	c.Assert(Hash(key{"a", 1}) != Hash(key{"a", 2}), Equals, true)
+	c.Assert(Hash([2]string{"ab", "c"}) != Hash([2]string{"a", "bc"}), Equals, true)
+
+	// Values that are == hash the same
+	negZero := math.Copysign(0, -1)
+	c.Assert(Hash(negZero), Equals, Hash(0.0))
+	c.Assert(Hash(float32(negZero)), Equals, Hash(float32(0)))
+	c.Assert(Hash(complex(negZero, 0)), Equals, Hash(complex(0, 0)))
+	c.Assert(Hash([1]float64{negZero}), Equals, Hash([1]float64{0}))
+
+	type point struct {
+		x, y float64
+	}
+	c.Assert(Hash(point{negZero, 1}), Equals, Hash(point{0, 1}))
+
+	c.Assert(Hash(int8(-1)), Equals, Hash(int8(-1)))
+	c.Assert(Hash(uint16(7)) != Hash(uint16(8)), Equals, true)
+	c.Assert(Hash(float32(1.5)) != Hash(float32(2.5)), Equals, true)
+}
@@ -0,0 +1,124 @@
+package set
+
+import (
+	"bytes"
+	"encoding/gob"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Sets encode as a list of their elements so they round trip through any
+// format the element type does. encoding/gob uses MarshalBinary.
+
+// MarshalYAML encodes the set as a YAML sequence
+func (set Set[T]) MarshalYAML() (interface{}, error) {
+	return set.ToSlice(), nil
+}
+
+// UnmarshalYAML recreates a set from a YAML sequence
+func (set *Set[T]) UnmarshalYAML(value *yaml.Node) error {
+	var list []T
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+
+	*set = New[T](list...)
+	return nil
+}
+
+// MarshalBinary gob encodes the elements of the set
+func (set Set[T]) MarshalBinary() ([]byte, error) {
+	return encodeBinary(set.ToSlice())
+}
+
+// UnmarshalBinary recreates a set encoded by MarshalBinary
+func (set *Set[T]) UnmarshalBinary(b []byte) error {
+	list, err := decodeBinary[T](b)
+	if err != nil {
+		return err
+	}
+
+	*set = New[T](list...)
+	return nil
+}
+
+// MarshalYAML encodes the set as a YAML sequence in ascending order
+func (set *OrderedSet[T]) MarshalYAML() (interface{}, error) {
+	return set.ToSlice(), nil
+}
+
+// UnmarshalYAML recreates a set from a YAML sequence
+func (set *OrderedSet[T]) UnmarshalYAML(value *yaml.Node) error {
+	var list []T
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+
+	*set = *NewOrdered[T](list...)
+	return nil
+}
+
+// MarshalBinary gob encodes the elements of the set in ascending order
+func (set *OrderedSet[T]) MarshalBinary() ([]byte, error) {
+	return encodeBinary(set.elems)
+}
+
+// UnmarshalBinary recreates a set encoded by MarshalBinary
+func (set *OrderedSet[T]) UnmarshalBinary(b []byte) error {
+	list, err := decodeBinary[T](b)
+	if err != nil {
+		return err
+	}
+
+	*set = *NewOrdered[T](list...)
+	return nil
+}
+
+// MarshalYAML encodes a snapshot of the set as a YAML sequence
+func (set *ConcurrentSet[T]) MarshalYAML() (interface{}, error) {
+	return set.ToSlice(), nil
+}
+
+// UnmarshalYAML replaces the contents of the set with a YAML sequence
+func (set *ConcurrentSet[T]) UnmarshalYAML(value *yaml.Node) error {
+	var list []T
+	if err := value.Decode(&list); err != nil {
+		return err
+	}
+
+	set.replace(list)
+	return nil
+}
+
+// MarshalBinary gob encodes a snapshot of the set
+func (set *ConcurrentSet[T]) MarshalBinary() ([]byte, error) {
+	return encodeBinary(set.ToSlice())
+}
+
+// UnmarshalBinary replaces the contents of the set with one encoded by
+// MarshalBinary
+func (set *ConcurrentSet[T]) UnmarshalBinary(b []byte) error {
+	list, err := decodeBinary[T](b)
+	if err != nil {
+		return err
+	}
+
+	set.replace(list)
+	return nil
+}
+
+func encodeBinary[T any](list []T) ([]byte, error) {
+	buf := &bytes.Buffer{}
+	if err := gob.NewEncoder(buf).Encode(list); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func decodeBinary[T any](b []byte) ([]T, error) {
+	var list []T
+	if err := gob.NewDecoder(bytes.NewReader(b)).Decode(&list); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
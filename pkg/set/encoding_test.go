@@ -0,0 +1,101 @@
+package set
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/json"
+
+	. "github.com/sjhitchner/toolbox/pkg/testing"
+	. "gopkg.in/check.v1"
+	"gopkg.in/yaml.v3"
+)
+
+type EncodingSuite struct{}
+
+var _ = Suite(&EncodingSuite{})
+
+type point struct {
+	X int
+	Y int
+}
+
+func (s *EncodingSuite) Test_JSON_Int(c *C) {
+	b, err := json.Marshal(New(7))
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "[7]")
+
+	actual := New[int]()
+	c.Assert(json.Unmarshal([]byte("[1,2,3]"), &actual), IsNil)
+	c.Assert(actual.Equals(New(1, 2, 3)), IsTrue)
+}
+
+func (s *EncodingSuite) Test_JSON_Struct(c *C) {
+	expected := New(point{1, 2}, point{3, 4})
+
+	b, err := json.Marshal(expected)
+	c.Assert(err, IsNil)
+	c.Assert(json.Valid(b), IsTrue)
+
+	actual := New[point]()
+	c.Assert(json.Unmarshal(b, &actual), IsNil)
+	c.Assert(expected.Equals(actual), IsTrue)
+}
+
+func (s *EncodingSuite) Test_YAML(c *C) {
+	type config struct {
+		Ports Set[int]            `yaml:"ports"`
+		Tags  *OrderedSet[string] `yaml:"tags"`
+	}
+
+	expected := config{
+		Ports: New(80, 443),
+		Tags:  NewOrdered("b", "a"),
+	}
+
+	b, err := yaml.Marshal(expected)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Contains, "tags:\n    - a\n    - b\n")
+
+	var actual config
+	c.Assert(yaml.Unmarshal(b, &actual), IsNil)
+	c.Assert(actual.Ports.Equals(expected.Ports), IsTrue)
+	c.Assert(actual.Tags.Equals(expected.Tags), IsTrue)
+
+	concurrent := NewConcurrent[int]()
+	c.Assert(yaml.Unmarshal([]byte("[1, 2]"), concurrent), IsNil)
+	c.Assert(concurrent.Contains(1, 2), IsTrue)
+}
+
+func (s *EncodingSuite) Test_Binary(c *C) {
+	expected := New(point{1, 2}, point{3, 4})
+
+	b, err := expected.MarshalBinary()
+	c.Assert(err, IsNil)
+
+	actual := New[point]()
+	c.Assert(actual.UnmarshalBinary(b), IsNil)
+	c.Assert(expected.Equals(actual), IsTrue)
+}
+
+func (s *EncodingSuite) Test_Gob(c *C) {
+	type message struct {
+		Seen    Set[string]
+		Ordered *OrderedSet[int]
+		Shared  *ConcurrentSet[int]
+	}
+
+	expected := message{
+		Seen:    New("a", "b"),
+		Ordered: NewOrdered(3, 1),
+		Shared:  NewConcurrent(5),
+	}
+
+	buf := &bytes.Buffer{}
+	c.Assert(gob.NewEncoder(buf).Encode(expected), IsNil)
+
+	var actual message
+	c.Assert(gob.NewDecoder(buf).Decode(&actual), IsNil)
+	c.Assert(actual.Seen.Equals(expected.Seen), IsTrue)
+	c.Assert(actual.Ordered.ToSlice(), DeepEquals, []int{1, 3})
+	c.Assert(actual.Shared.Contains(5), IsTrue)
+}
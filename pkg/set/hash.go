@@ -0,0 +1,80 @@
+package set
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"hash/fnv"
+	"io"
+	"math"
+	"reflect"
+)
+
+// Hash stable fnv64a hash of v, values that are == hash the same in every
+// process, including 0 and -0. It is used to pick shards and partitions.
+func Hash(v any) uint64 {
+	h := fnv.New64a()
+	hashValue(h, reflect.ValueOf(v))
+	return h.Sum64()
+}
+
+// hashValue write v to h field by field, every comparable kind is handled
+// explicitly so equal values write the same bytes
+func hashValue(h hash.Hash64, v reflect.Value) {
+	var buf [8]byte
+	word := func(n uint64) {
+		binary.LittleEndian.PutUint64(buf[:], n)
+		h.Write(buf[:])
+	}
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		word(0)
+	case reflect.String:
+		io.WriteString(h, v.String())
+		word(uint64(v.Len()))
+	case reflect.Bool:
+		if v.Bool() {
+			word(1)
+		} else {
+			word(0)
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		word(uint64(v.Int()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		word(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		word(floatBits(v.Float()))
+	case reflect.Complex64, reflect.Complex128:
+		c := v.Complex()
+		word(floatBits(real(c)))
+		word(floatBits(imag(c)))
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(h, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(h, v.Field(i))
+		}
+	case reflect.Pointer, reflect.Chan, reflect.UnsafePointer:
+		word(uint64(v.Pointer()))
+	case reflect.Interface:
+		if v.IsNil() {
+			word(0)
+			return
+		}
+		io.WriteString(h, v.Elem().Type().String())
+		hashValue(h, v.Elem())
+	default:
+		fmt.Fprintf(h, "%v", v)
+	}
+}
+
+// floatBits bits of f with -0 folded into 0 as they compare equal
+func floatBits(f float64) uint64 {
+	if f == 0 {
+		return 0
+	}
+	return math.Float64bits(f)
+}
@@ -0,0 +1,35 @@
+//go:build go1.23
+
+package set
+
+import (
+	"iter"
+)
+
+// All iterate over the set, unlike Iter and Iterator stopping a range loop
+// early does not leave a goroutine behind
+func (set Set[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for elem := range set {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// All iterate over the set in ascending order
+func (set *OrderedSet[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, elem := range set.elems {
+			if !yield(elem) {
+				return
+			}
+		}
+	}
+}
+
+// All iterate over a snapshot of the set
+func (set *ConcurrentSet[T]) All() iter.Seq[T] {
+	return set.Snapshot().All()
+}
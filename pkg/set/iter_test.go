@@ -0,0 +1,34 @@
+//go:build go1.23
+
+package set
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+func (s *SetSuite) Test_All(c *C) {
+	s1 := New[string]("test1", "test2", "test3")
+
+	s2 := New[string]()
+	for elem := range s1.All() {
+		s2.Add(elem)
+	}
+	c.Assert(s1.Equals(s2), Equals, true)
+
+	var count int
+	for _ = range s1.All() {
+		count++
+		break
+	}
+	c.Assert(count, Equals, 1)
+}
+
+func (s *OrderedSuite) Test_All(c *C) {
+	s1 := NewOrdered(3, 1, 2)
+
+	var out []int
+	for elem := range s1.All() {
+		out = append(out, elem)
+	}
+	c.Assert(out, DeepEquals, []int{1, 2, 3})
+}
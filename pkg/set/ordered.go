@@ -0,0 +1,339 @@
+package set
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"golang.org/x/exp/constraints"
+)
+
+// OrderedSet set kept in ascending order, iteration, String and MarshalJSON
+// are deterministic so output can be compared and diffed. Elements are held
+// in a sorted slice, lookups are O(log n) and inserts O(n).
+type OrderedSet[T constraints.Ordered] struct {
+	elems []T
+}
+
+// NewOrdered create new ordered set
+func NewOrdered[T constraints.Ordered](s ...T) *OrderedSet[T] {
+	set := &OrderedSet[T]{
+		elems: make([]T, 0, len(s)),
+	}
+	set.Add(s...)
+	return set
+}
+
+// search index of the first element >= v, and whether it equals v
+func (set *OrderedSet[T]) search(v T) (int, bool) {
+	i := sort.Search(len(set.elems), func(i int) bool {
+		return set.elems[i] >= v
+	})
+	return i, i < len(set.elems) && set.elems[i] == v
+}
+
+// Add to set
+func (set *OrderedSet[T]) Add(s ...T) {
+	for _, v := range s {
+		set.CheckAndAdd(v)
+	}
+}
+
+// CheckAndAdd check if exists and add
+func (set *OrderedSet[T]) CheckAndAdd(v T) bool {
+	i, found := set.search(v)
+	if found {
+		return false
+	}
+
+	var zero T
+	set.elems = append(set.elems, zero)
+	copy(set.elems[i+1:], set.elems[i:])
+	set.elems[i] = v
+	return true
+}
+
+// Merge Sets
+func (set *OrderedSet[T]) Merge(others ...*OrderedSet[T]) {
+	for _, other := range others {
+		set.elems = mergeSorted(set.elems, other.elems)
+	}
+}
+
+// Contains does value exist in set
+func (set *OrderedSet[T]) Contains(s ...T) bool {
+	for _, v := range s {
+		if _, found := set.search(v); !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *OrderedSet[T]) IsSubset(other *OrderedSet[T]) bool {
+	if set.Cardinality() > other.Cardinality() {
+		return false
+	}
+
+	for _, elem := range set.elems {
+		if !other.Contains(elem) {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *OrderedSet[T]) IsSuperset(other *OrderedSet[T]) bool {
+	return other.IsSubset(set)
+}
+
+func (set *OrderedSet[T]) Union(other ...*OrderedSet[T]) *OrderedSet[T] {
+	union := set.Clone()
+	union.Merge(other...)
+	return union
+}
+
+func (set *OrderedSet[T]) Intersect(other *OrderedSet[T]) *OrderedSet[T] {
+	intersection := NewOrdered[T]()
+
+	a, b := set.elems, other.elems
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			intersection.elems = append(intersection.elems, a[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+	return intersection
+}
+
+func (set *OrderedSet[T]) Difference(other *OrderedSet[T]) *OrderedSet[T] {
+	difference := NewOrdered[T]()
+	for _, elem := range set.elems {
+		if !other.Contains(elem) {
+			difference.elems = append(difference.elems, elem)
+		}
+	}
+	return difference
+}
+
+func (set *OrderedSet[T]) SymmetricDifference(other *OrderedSet[T]) *OrderedSet[T] {
+	aDiff := set.Difference(other)
+	bDiff := other.Difference(set)
+	return aDiff.Union(bDiff)
+}
+
+func (set *OrderedSet[T]) HasOverlap(other *OrderedSet[T]) bool {
+	a, b := set.elems, other.elems
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			a = a[1:]
+		case a[0] > b[0]:
+			b = b[1:]
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func (set *OrderedSet[T]) Clear() {
+	clear(set.elems)
+	set.elems = set.elems[:0]
+}
+
+func (set *OrderedSet[T]) Remove(v T) {
+	i, found := set.search(v)
+	if found {
+		set.elems = append(set.elems[:i], set.elems[i+1:]...)
+	}
+}
+
+func (set *OrderedSet[T]) Cardinality() int {
+	return len(set.elems)
+}
+
+func (set *OrderedSet[T]) IsEmpty() bool {
+	return len(set.elems) == 0
+}
+
+// Min smallest element, false if the set is empty
+func (set *OrderedSet[T]) Min() (T, bool) {
+	var zero T
+	if set.IsEmpty() {
+		return zero, false
+	}
+	return set.elems[0], true
+}
+
+// Max largest element, false if the set is empty
+func (set *OrderedSet[T]) Max() (T, bool) {
+	var zero T
+	if set.IsEmpty() {
+		return zero, false
+	}
+	return set.elems[len(set.elems)-1], true
+}
+
+// Floor largest element <= v, false if there is none
+func (set *OrderedSet[T]) Floor(v T) (T, bool) {
+	var zero T
+
+	i, found := set.search(v)
+	if found {
+		return set.elems[i], true
+	}
+	if i == 0 {
+		return zero, false
+	}
+	return set.elems[i-1], true
+}
+
+// Ceiling smallest element >= v, false if there is none
+func (set *OrderedSet[T]) Ceiling(v T) (T, bool) {
+	var zero T
+
+	i, _ := set.search(v)
+	if i == len(set.elems) {
+		return zero, false
+	}
+	return set.elems[i], true
+}
+
+// Range elements between lo and hi inclusive in ascending order
+func (set *OrderedSet[T]) Range(lo, hi T) []T {
+	if lo > hi {
+		return []T{}
+	}
+
+	start, _ := set.search(lo)
+	end, found := set.search(hi)
+	if found {
+		end++
+	}
+
+	out := make([]T, end-start)
+	copy(out, set.elems[start:end])
+	return out
+}
+
+func (set *OrderedSet[T]) Iter() <-chan T {
+	ch := make(chan T)
+	go func() {
+		for _, elem := range set.elems {
+			ch <- elem
+		}
+		close(ch)
+	}()
+
+	return ch
+}
+
+func (set *OrderedSet[T]) Iterator() *Iterator[T] {
+	iterator, ch, stopCh := newIterator[T]()
+
+	go func() {
+	L:
+		for _, elem := range set.elems {
+			select {
+			case <-stopCh:
+				break L
+			case ch <- elem:
+			}
+		}
+		close(ch)
+	}()
+
+	return iterator
+}
+
+func (set *OrderedSet[T]) Equals(other *OrderedSet[T]) bool {
+	if set.Cardinality() != other.Cardinality() {
+		return false
+	}
+
+	for i, elem := range set.elems {
+		if other.elems[i] != elem {
+			return false
+		}
+	}
+	return true
+}
+
+func (set *OrderedSet[T]) Clone() *OrderedSet[T] {
+	elems := make([]T, len(set.elems))
+	copy(elems, set.elems)
+	return &OrderedSet[T]{elems: elems}
+}
+
+func (set *OrderedSet[T]) String() string {
+	buf := &bytes.Buffer{}
+	fmt.Fprint(buf, "OrderedSet{")
+
+	for i, elem := range set.elems {
+		if i > 0 {
+			buf.WriteString(", ")
+		}
+		fmt.Fprintf(buf, "%v", elem)
+	}
+
+	buf.WriteRune('}')
+	return buf.String()
+}
+
+// ToSlice elements in ascending order
+func (set *OrderedSet[T]) ToSlice() []T {
+	out := make([]T, len(set.elems))
+	copy(out, set.elems)
+	return out
+}
+
+// ToSet convert to an unordered Set
+func (set *OrderedSet[T]) ToSet() Set[T] {
+	return New[T](set.elems...)
+}
+
+// MarshalJSON creates a JSON array of the elements in ascending order
+func (set *OrderedSet[T]) MarshalJSON() ([]byte, error) {
+	if set.elems == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(set.elems)
+}
+
+// UnmarshalJSON recreates a set from a JSON array
+func (set *OrderedSet[T]) UnmarshalJSON(b []byte) error {
+	var list []T
+	if err := json.Unmarshal(b, &list); err != nil {
+		return err
+	}
+
+	*set = *NewOrdered[T](list...)
+	return nil
+}
+
+// mergeSorted union of two ascending slices without duplicates
+func mergeSorted[T constraints.Ordered](a, b []T) []T {
+	out := make([]T, 0, len(a)+len(b))
+	for len(a) > 0 && len(b) > 0 {
+		switch {
+		case a[0] < b[0]:
+			out = append(out, a[0])
+			a = a[1:]
+		case a[0] > b[0]:
+			out = append(out, b[0])
+			b = b[1:]
+		default:
+			out = append(out, a[0])
+			a, b = a[1:], b[1:]
+		}
+	}
+	out = append(out, a...)
+	return append(out, b...)
+}
@@ -0,0 +1,143 @@
+package set
+
+import (
+	"encoding/json"
+
+	. "gopkg.in/check.v1"
+)
+
+type OrderedSuite struct{}
+
+var _ = Suite(&OrderedSuite{})
+
+func (s *OrderedSuite) Test_Add(c *C) {
+	s1 := NewOrdered("c", "a", "b")
+	s1.Add("a", "e")
+	c.Assert(s1.CheckAndAdd("d"), Equals, true)
+	c.Assert(s1.CheckAndAdd("d"), Equals, false)
+
+	c.Assert(s1.Cardinality(), Equals, 5)
+	c.Assert(s1.ToSlice(), DeepEquals, []string{"a", "b", "c", "d", "e"})
+	c.Assert(s1.Contains("a", "e"), Equals, true)
+	c.Assert(s1.Contains("f"), Equals, false)
+
+	s1.Remove("c")
+	s1.Remove("z")
+	c.Assert(s1.ToSlice(), DeepEquals, []string{"a", "b", "d", "e"})
+
+	s1.Clear()
+	c.Assert(s1.IsEmpty(), Equals, true)
+
+	b, err := json.Marshal(s1)
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "[]")
+
+	b, err = json.Marshal(&OrderedSet[int]{})
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "[]")
+}
+
+func (s *OrderedSuite) Test_MinMax(c *C) {
+	s1 := NewOrdered[int]()
+	_, ok := s1.Min()
+	c.Assert(ok, Equals, false)
+
+	s1.Add(5, 1, 9)
+
+	min, ok := s1.Min()
+	c.Assert(ok, Equals, true)
+	c.Assert(min, Equals, 1)
+
+	max, ok := s1.Max()
+	c.Assert(ok, Equals, true)
+	c.Assert(max, Equals, 9)
+}
+
+func (s *OrderedSuite) Test_FloorCeiling(c *C) {
+	s1 := NewOrdered(10, 20, 30)
+
+	v, ok := s1.Floor(20)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 20)
+
+	v, ok = s1.Floor(25)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 20)
+
+	_, ok = s1.Floor(5)
+	c.Assert(ok, Equals, false)
+
+	v, ok = s1.Ceiling(25)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 30)
+
+	v, ok = s1.Ceiling(5)
+	c.Assert(ok, Equals, true)
+	c.Assert(v, Equals, 10)
+
+	_, ok = s1.Ceiling(31)
+	c.Assert(ok, Equals, false)
+}
+
+func (s *OrderedSuite) Test_Range(c *C) {
+	s1 := NewOrdered(1, 3, 5, 7, 9)
+
+	c.Assert(s1.Range(3, 7), DeepEquals, []int{3, 5, 7})
+	c.Assert(s1.Range(2, 8), DeepEquals, []int{3, 5, 7})
+	c.Assert(s1.Range(0, 100), DeepEquals, []int{1, 3, 5, 7, 9})
+	c.Assert(s1.Range(10, 20), DeepEquals, []int{})
+	c.Assert(s1.Range(7, 3), DeepEquals, []int{})
+}
+
+func (s *OrderedSuite) Test_Operations(c *C) {
+	a := NewOrdered(1, 2, 3, 4)
+	b := NewOrdered(3, 4, 5)
+
+	c.Assert(a.Union(b).ToSlice(), DeepEquals, []int{1, 2, 3, 4, 5})
+	c.Assert(a.Intersect(b).ToSlice(), DeepEquals, []int{3, 4})
+	c.Assert(a.Difference(b).ToSlice(), DeepEquals, []int{1, 2})
+	c.Assert(a.SymmetricDifference(b).ToSlice(), DeepEquals, []int{1, 2, 5})
+	c.Assert(a.HasOverlap(b), Equals, true)
+	c.Assert(a.HasOverlap(NewOrdered(8, 9)), Equals, false)
+
+	c.Assert(NewOrdered(2, 3).IsSubset(a), Equals, true)
+	c.Assert(a.IsSuperset(NewOrdered(2, 3)), Equals, true)
+	c.Assert(b.IsSubset(a), Equals, false)
+
+	c.Assert(a.Equals(NewOrdered(4, 3, 2, 1)), Equals, true)
+	c.Assert(a.Equals(b), Equals, false)
+
+	clone := a.Clone()
+	clone.Add(10)
+	c.Assert(a.Contains(10), Equals, false)
+
+	c.Assert(a.ToSet().Equals(New(1, 2, 3, 4)), Equals, true)
+}
+
+func (s *OrderedSuite) Test_Iterator(c *C) {
+	s1 := NewOrdered("b", "a", "c")
+
+	var out []string
+	for v := range s1.Iter() {
+		out = append(out, v)
+	}
+	c.Assert(out, DeepEquals, []string{"a", "b", "c"})
+
+	it := s1.Iterator()
+	c.Assert(<-it.C, Equals, "a")
+	it.Stop()
+}
+
+func (s *OrderedSuite) Test_String(c *C) {
+	c.Assert(NewOrdered(3, 1, 2).String(), Equals, "OrderedSet{1, 2, 3}")
+}
+
+func (s *OrderedSuite) Test_JSON(c *C) {
+	b, err := json.Marshal(NewOrdered(3, 1, 2))
+	c.Assert(err, IsNil)
+	c.Assert(string(b), Equals, "[1,2,3]")
+
+	s1 := NewOrdered[int]()
+	c.Assert(json.Unmarshal([]byte("[5,4,4]"), s1), IsNil)
+	c.Assert(s1.ToSlice(), DeepEquals, []int{4, 5})
+}
@@ -0,0 +1,32 @@
+package set
+
+import (
+	"fmt"
+)
+
+// Pair ordered pair of values, comparable so can be held in a Set
+type Pair[A comparable, B comparable] struct {
+	First  A
+	Second B
+}
+
+func (pair Pair[A, B]) Equal(other Pair[A, B]) bool {
+	return pair.First == other.First && pair.Second == other.Second
+}
+
+func (pair Pair[A, B]) String() string {
+	return fmt.Sprintf("(%v, %v)", pair.First, pair.Second)
+}
+
+// CartesianProduct every pair of an element of a and an element of b
+func CartesianProduct[A comparable, B comparable](a Set[A], b Set[B]) Set[Pair[A, B]] {
+	product := make(Set[Pair[A, B]], a.Cardinality()*b.Cardinality())
+
+	for i := range a {
+		for j := range b {
+			product.Add(Pair[A, B]{First: i, Second: j})
+		}
+	}
+
+	return product
+}
@@ -0,0 +1,252 @@
+package probabilistic
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+	"math/bits"
+)
+
+// BloomFilter answers whether an item has been added. False positives occur
+// at the configured rate, false negatives never do. Not safe for concurrent
+// use.
+type BloomFilter struct {
+	m    uint64
+	k    uint64
+	n    uint64
+	bits []uint64
+}
+
+// NewBloomFilter create a filter sized for n items at false positive rate p
+func NewBloomFilter(n uint64, p float64) *BloomFilter {
+	m, k := EstimateParameters(n, p)
+	return NewBloomFilterSize(m, k)
+}
+
+// NewBloomFilterSize create a filter of m bits using k hash functions, k is
+// limited to 64
+func NewBloomFilterSize(m, k uint64) *BloomFilter {
+	if m < 1 {
+		m = 1
+	}
+	k = min(max(k, 1), maxHashes)
+
+	return &BloomFilter{
+		m:    m,
+		k:    k,
+		bits: make([]uint64, (m+63)/64),
+	}
+}
+
+// Cap number of bits in the filter
+func (t *BloomFilter) Cap() uint64 {
+	return t.m
+}
+
+// K number of hash functions
+func (t *BloomFilter) K() uint64 {
+	return t.k
+}
+
+// Count number of items added, duplicates included
+func (t *BloomFilter) Count() uint64 {
+	return t.n
+}
+
+// Add item to the filter
+func (t *BloomFilter) Add(b []byte) {
+	for _, loc := range locations(b, t.k, t.m) {
+		t.bits[loc/64] |= 1 << (loc % 64)
+	}
+	t.n++
+}
+
+// AddString add s to the filter
+func (t *BloomFilter) AddString(s string) {
+	t.Add([]byte(s))
+}
+
+// Test whether item may have been added, false means it definitely was not
+func (t *BloomFilter) Test(b []byte) bool {
+	for _, loc := range locations(b, t.k, t.m) {
+		if t.bits[loc/64]&(1<<(loc%64)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TestString test whether s may have been added
+func (t *BloomFilter) TestString(s string) bool {
+	return t.Test([]byte(s))
+}
+
+// TestAndAdd test for item then add it, true if it may already have existed
+func (t *BloomFilter) TestAndAdd(b []byte) bool {
+	found := t.Test(b)
+	t.Add(b)
+	return found
+}
+
+// FalsePositiveRate estimated from the fraction of bits set
+func (t *BloomFilter) FalsePositiveRate() float64 {
+	var set int
+	for _, w := range t.bits {
+		set += bits.OnesCount64(w)
+	}
+	return math.Pow(float64(set)/float64(t.m), float64(t.k))
+}
+
+// Merge add every item of other, both filters must have the same shape
+func (t *BloomFilter) Merge(other *BloomFilter) error {
+	if t.m != other.m || t.k != other.k {
+		return ErrIncompatible
+	}
+
+	for i := range t.bits {
+		t.bits[i] |= other.bits[i]
+	}
+	t.n += other.n
+	return nil
+}
+
+// Clear remove every item
+func (t *BloomFilter) Clear() {
+	for i := range t.bits {
+		t.bits[i] = 0
+	}
+	t.n = 0
+}
+
+// MarshalBinary encode the filter
+func (t *BloomFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 2, 2+24+8*len(t.bits))
+	data[0], data[1] = typeBloom, version
+
+	data = binary.BigEndian.AppendUint64(data, t.m)
+	data = binary.BigEndian.AppendUint64(data, t.k)
+	data = binary.BigEndian.AppendUint64(data, t.n)
+	for _, w := range t.bits {
+		data = binary.BigEndian.AppendUint64(data, w)
+	}
+	return data, nil
+}
+
+// UnmarshalBinary decode a filter encoded by MarshalBinary
+func (t *BloomFilter) UnmarshalBinary(data []byte) error {
+	data, err := header(data, typeBloom)
+	if err != nil {
+		return err
+	}
+	if len(data) < 24 {
+		return ErrInvalidData
+	}
+
+	m := binary.BigEndian.Uint64(data)
+	k := binary.BigEndian.Uint64(data[8:])
+	n := binary.BigEndian.Uint64(data[16:])
+	data = data[24:]
+
+	// Bound m by the payload first so the word count cannot overflow
+	if m == 0 || m > uint64(len(data))*8 || k == 0 || k > maxHashes {
+		return ErrInvalidData
+	}
+
+	words := (m + 63) / 64
+	if uint64(len(data)) != words*8 {
+		return ErrInvalidData
+	}
+
+	bits := make([]uint64, words)
+	for i := range bits {
+		bits[i] = binary.BigEndian.Uint64(data[i*8:])
+	}
+
+	*t = BloomFilter{m: m, k: k, n: n, bits: bits}
+	return nil
+}
+
+// MarshalJSON encode the filter as a base64 string of its binary form
+func (t *BloomFilter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(t)
+}
+
+// UnmarshalJSON decode a filter encoded by MarshalJSON
+func (t *BloomFilter) UnmarshalJSON(b []byte) error {
+	return unmarshalJSON(b, t)
+}
+
+// WriteTo write the binary form of the filter to w
+func (t *BloomFilter) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, t)
+}
+
+// ReadFrom read a filter written by WriteTo
+func (t *BloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	return readFrom(r, t)
+}
+
+// maxSerialized guards ReadFrom against allocating for a corrupt length
+const maxSerialized = 1 << 34
+
+type binaryCodec interface {
+	MarshalBinary() ([]byte, error)
+	UnmarshalBinary([]byte) error
+}
+
+func marshalJSON(t binaryCodec) ([]byte, error) {
+	data, err := t.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(data)
+}
+
+func unmarshalJSON(b []byte, t binaryCodec) error {
+	var data []byte
+	if err := json.Unmarshal(b, &data); err != nil {
+		return err
+	}
+	return t.UnmarshalBinary(data)
+}
+
+// writeTo write the binary form prefixed by its length
+func writeTo(w io.Writer, t binaryCodec) (int64, error) {
+	data, err := t.MarshalBinary()
+	if err != nil {
+		return 0, err
+	}
+
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(data)))
+
+	n, err := w.Write(size[:])
+	if err != nil {
+		return int64(n), err
+	}
+
+	m, err := w.Write(data)
+	return int64(n + m), err
+}
+
+func readFrom(r io.Reader, t binaryCodec) (int64, error) {
+	var size [8]byte
+	n, err := io.ReadFull(r, size[:])
+	if err != nil {
+		return int64(n), err
+	}
+
+	length := binary.BigEndian.Uint64(size[:])
+	if length > maxSerialized {
+		return int64(n), ErrInvalidData
+	}
+
+	data := make([]byte, length)
+	m, err := io.ReadFull(r, data)
+	if err != nil {
+		return int64(n + m), err
+	}
+
+	return int64(n + m), t.UnmarshalBinary(data)
+}
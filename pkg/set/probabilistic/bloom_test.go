@@ -0,0 +1,109 @@
+package probabilistic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	. "gopkg.in/check.v1"
+)
+
+type BloomSuite struct{}
+
+var _ = Suite(&BloomSuite{})
+
+func (s *BloomSuite) Test_EstimateParameters(c *C) {
+	m, k := EstimateParameters(1000, 0.01)
+	c.Assert(m, Equals, uint64(9586))
+	c.Assert(k, Equals, uint64(7))
+}
+
+func (s *BloomSuite) Test_Bloom(c *C) {
+	f := NewBloomFilter(1000, 0.01)
+
+	for i := 0; i < 1000; i++ {
+		f.AddString(fmt.Sprintf("id-%d", i))
+	}
+	c.Assert(f.Count(), Equals, uint64(1000))
+
+	// No false negatives
+	for i := 0; i < 1000; i++ {
+		c.Assert(f.TestString(fmt.Sprintf("id-%d", i)), Equals, true)
+	}
+
+	// False positives close to the configured rate
+	var fp int
+	for i := 0; i < 10000; i++ {
+		if f.TestString(fmt.Sprintf("other-%d", i)) {
+			fp++
+		}
+	}
+	c.Assert(fp < 200, Equals, true, Commentf("false positives %d", fp))
+	c.Assert(f.FalsePositiveRate() < 0.02, Equals, true)
+
+	c.Assert(f.TestAndAdd([]byte("new")), Equals, false)
+	c.Assert(f.TestAndAdd([]byte("new")), Equals, true)
+
+	f.Clear()
+	c.Assert(f.TestString("id-1"), Equals, false)
+}
+
+func (s *BloomSuite) Test_Bloom_Merge(c *C) {
+	a := NewBloomFilter(100, 0.01)
+	b := NewBloomFilter(100, 0.01)
+	a.AddString("a")
+	b.AddString("b")
+
+	c.Assert(a.Merge(b), IsNil)
+	c.Assert(a.TestString("a"), Equals, true)
+	c.Assert(a.TestString("b"), Equals, true)
+	c.Assert(a.Count(), Equals, uint64(2))
+
+	c.Assert(a.Merge(NewBloomFilter(1000, 0.01)), Equals, ErrIncompatible)
+}
+
+func (s *BloomSuite) Test_Bloom_Serialize(c *C) {
+	f := NewBloomFilter(100, 0.01)
+	f.AddString("a")
+
+	data, err := f.MarshalBinary()
+	c.Assert(err, IsNil)
+
+	var binary BloomFilter
+	c.Assert(binary.UnmarshalBinary(data), IsNil)
+	c.Assert(binary.TestString("a"), Equals, true)
+	c.Assert(binary.Count(), Equals, uint64(1))
+
+	b, err := json.Marshal(f)
+	c.Assert(err, IsNil)
+
+	var decoded BloomFilter
+	c.Assert(json.Unmarshal(b, &decoded), IsNil)
+	c.Assert(decoded.TestString("a"), Equals, true)
+	c.Assert(decoded.Cap(), Equals, f.Cap())
+	c.Assert(decoded.K(), Equals, f.K())
+
+	buf := &bytes.Buffer{}
+	_, err = f.WriteTo(buf)
+	c.Assert(err, IsNil)
+
+	var read BloomFilter
+	_, err = read.ReadFrom(buf)
+	c.Assert(err, IsNil)
+	c.Assert(read.TestString("a"), Equals, true)
+
+	c.Assert(read.UnmarshalBinary([]byte("junk")), Equals, ErrInvalidData)
+	c.Assert(read.UnmarshalBinary(data[:len(data)-1]), Equals, ErrInvalidData)
+
+	// A corrupt hash count would make every lookup spin
+	c.Assert(read.UnmarshalBinary(withK(data, 1<<40)), Equals, ErrInvalidData)
+	c.Assert(read.UnmarshalBinary(withK(data, maxHashes+1)), Equals, ErrInvalidData)
+	c.Assert(read.UnmarshalBinary(withK(data, maxHashes)), IsNil)
+
+	// A corrupt size must not overflow the word count
+	c.Assert(read.UnmarshalBinary(withM(data, ^uint64(0))), Equals, ErrInvalidData)
+	c.Assert(read.UnmarshalBinary(withM(data[:26], ^uint64(0))), Equals, ErrInvalidData)
+	c.Assert(read.UnmarshalBinary(withM(data, f.Cap()+64)), Equals, ErrInvalidData)
+
+	c.Assert(NewBloomFilterSize(128, 1000).k, Equals, uint64(maxHashes))
+}
@@ -0,0 +1,192 @@
+package probabilistic
+
+import (
+	"encoding/binary"
+	"io"
+)
+
+// CountingBloomFilter bloom filter with a counter per position instead of a
+// bit so items can be removed. Counters saturate at 255 and are never
+// decremented once saturated, which keeps Test free of false negatives at
+// the cost of leaving those positions set. Not safe for concurrent use.
+type CountingBloomFilter struct {
+	m        uint64
+	k        uint64
+	n        uint64
+	counters []uint8
+}
+
+// NewCountingBloomFilter create a filter sized for n items at false
+// positive rate p
+func NewCountingBloomFilter(n uint64, p float64) *CountingBloomFilter {
+	m, k := EstimateParameters(n, p)
+	return NewCountingBloomFilterSize(m, k)
+}
+
+// NewCountingBloomFilterSize create a filter of m counters using k hash
+// functions, k is limited to 64
+func NewCountingBloomFilterSize(m, k uint64) *CountingBloomFilter {
+	if m < 1 {
+		m = 1
+	}
+	k = min(max(k, 1), maxHashes)
+
+	return &CountingBloomFilter{
+		m:        m,
+		k:        k,
+		counters: make([]uint8, m),
+	}
+}
+
+// Cap number of counters in the filter
+func (t *CountingBloomFilter) Cap() uint64 {
+	return t.m
+}
+
+// K number of hash functions
+func (t *CountingBloomFilter) K() uint64 {
+	return t.k
+}
+
+// Count number of items added less those removed
+func (t *CountingBloomFilter) Count() uint64 {
+	return t.n
+}
+
+// Add item to the filter
+func (t *CountingBloomFilter) Add(b []byte) {
+	for _, loc := range locations(b, t.k, t.m) {
+		if t.counters[loc] < 255 {
+			t.counters[loc]++
+		}
+	}
+	t.n++
+}
+
+// AddString add s to the filter
+func (t *CountingBloomFilter) AddString(s string) {
+	t.Add([]byte(s))
+}
+
+// Remove item from the filter, false if it was not in the filter. Removing
+// an item that was never added can remove other items.
+func (t *CountingBloomFilter) Remove(b []byte) bool {
+	locs := locations(b, t.k, t.m)
+	for _, loc := range locs {
+		if t.counters[loc] == 0 {
+			return false
+		}
+	}
+
+	for _, loc := range locs {
+		if t.counters[loc] < 255 {
+			t.counters[loc]--
+		}
+	}
+	if t.n > 0 {
+		t.n--
+	}
+	return true
+}
+
+// RemoveString remove s from the filter
+func (t *CountingBloomFilter) RemoveString(s string) bool {
+	return t.Remove([]byte(s))
+}
+
+// Test whether item may be in the filter, false means it definitely is not
+func (t *CountingBloomFilter) Test(b []byte) bool {
+	for _, loc := range locations(b, t.k, t.m) {
+		if t.counters[loc] == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// TestString test whether s may be in the filter
+func (t *CountingBloomFilter) TestString(s string) bool {
+	return t.Test([]byte(s))
+}
+
+// Merge add every item of other, both filters must have the same shape
+func (t *CountingBloomFilter) Merge(other *CountingBloomFilter) error {
+	if t.m != other.m || t.k != other.k {
+		return ErrIncompatible
+	}
+
+	for i, c := range other.counters {
+		sum := uint16(t.counters[i]) + uint16(c)
+		if sum > 255 {
+			sum = 255
+		}
+		t.counters[i] = uint8(sum)
+	}
+	t.n += other.n
+	return nil
+}
+
+// Clear remove every item
+func (t *CountingBloomFilter) Clear() {
+	for i := range t.counters {
+		t.counters[i] = 0
+	}
+	t.n = 0
+}
+
+// MarshalBinary encode the filter
+func (t *CountingBloomFilter) MarshalBinary() ([]byte, error) {
+	data := make([]byte, 2, 2+24+len(t.counters))
+	data[0], data[1] = typeCounting, version
+
+	data = binary.BigEndian.AppendUint64(data, t.m)
+	data = binary.BigEndian.AppendUint64(data, t.k)
+	data = binary.BigEndian.AppendUint64(data, t.n)
+	return append(data, t.counters...), nil
+}
+
+// UnmarshalBinary decode a filter encoded by MarshalBinary
+func (t *CountingBloomFilter) UnmarshalBinary(data []byte) error {
+	data, err := header(data, typeCounting)
+	if err != nil {
+		return err
+	}
+	if len(data) < 24 {
+		return ErrInvalidData
+	}
+
+	m := binary.BigEndian.Uint64(data)
+	k := binary.BigEndian.Uint64(data[8:])
+	n := binary.BigEndian.Uint64(data[16:])
+	data = data[24:]
+
+	if m == 0 || m != uint64(len(data)) || k == 0 || k > maxHashes {
+		return ErrInvalidData
+	}
+
+	counters := make([]uint8, m)
+	copy(counters, data)
+
+	*t = CountingBloomFilter{m: m, k: k, n: n, counters: counters}
+	return nil
+}
+
+// MarshalJSON encode the filter as a base64 string of its binary form
+func (t *CountingBloomFilter) MarshalJSON() ([]byte, error) {
+	return marshalJSON(t)
+}
+
+// UnmarshalJSON decode a filter encoded by MarshalJSON
+func (t *CountingBloomFilter) UnmarshalJSON(b []byte) error {
+	return unmarshalJSON(b, t)
+}
+
+// WriteTo write the binary form of the filter to w
+func (t *CountingBloomFilter) WriteTo(w io.Writer) (int64, error) {
+	return writeTo(w, t)
+}
+
+// ReadFrom read a filter written by WriteTo
+func (t *CountingBloomFilter) ReadFrom(r io.Reader) (int64, error) {
+	return readFrom(r, t)
+}
@@ -0,0 +1,59 @@
+package probabilistic
+
+import (
+	. "gopkg.in/check.v1"
+)
+
+type CountingSuite struct{}
+
+var _ = Suite(&CountingSuite{})
+
+func (s *CountingSuite) Test_Counting(c *C) {
+	f := NewCountingBloomFilter(100, 0.01)
+	f.AddString("a")
+	f.AddString("b")
+
+	c.Assert(f.TestString("a"), Equals, true)
+	c.Assert(f.RemoveString("a"), Equals, true)
+	c.Assert(f.TestString("a"), Equals, false)
+	c.Assert(f.TestString("b"), Equals, true)
+	c.Assert(f.RemoveString("a"), Equals, false)
+	c.Assert(f.Count(), Equals, uint64(1))
+}
+
+func (s *CountingSuite) Test_Counting_Merge(c *C) {
+	a := NewCountingBloomFilter(100, 0.01)
+	b := NewCountingBloomFilter(100, 0.01)
+	a.AddString("x")
+	b.AddString("x")
+
+	c.Assert(a.Merge(b), IsNil)
+
+	// Added twice so survives one removal
+	c.Assert(a.RemoveString("x"), Equals, true)
+	c.Assert(a.TestString("x"), Equals, true)
+
+	c.Assert(a.Merge(NewCountingBloomFilterSize(10, 1)), Equals, ErrIncompatible)
+}
+
+func (s *CountingSuite) Test_Counting_Serialize(c *C) {
+	f := NewCountingBloomFilter(100, 0.01)
+	f.AddString("a")
+
+	data, err := f.MarshalBinary()
+	c.Assert(err, IsNil)
+
+	var decoded CountingBloomFilter
+	c.Assert(decoded.UnmarshalBinary(data), IsNil)
+	c.Assert(decoded.TestString("a"), Equals, true)
+
+	// A bloom filter cannot be decoded as a counting filter
+	bloom, _ := NewBloomFilter(100, 0.01).MarshalBinary()
+	c.Assert(decoded.UnmarshalBinary(bloom), Equals, ErrInvalidData)
+
+	c.Assert(decoded.UnmarshalBinary(withK(data, maxHashes+1)), Equals, ErrInvalidData)
+	c.Assert(decoded.UnmarshalBinary(withM(data, ^uint64(0))), Equals, ErrInvalidData)
+	c.Assert(decoded.UnmarshalBinary(withM(data[:26], ^uint64(0))), Equals, ErrInvalidData)
+
+	c.Assert(NewCountingBloomFilterSize(16, 1000).k, Equals, uint64(maxHashes))
+}
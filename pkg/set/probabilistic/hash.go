@@ -0,0 +1,87 @@
+// Package probabilistic approximate set structures that trade exactness for
+// a fixed memory footprint. BloomFilter and CountingBloomFilter answer
+// membership with a tunable false positive rate, HyperLogLog estimates the
+// number of distinct items. All of them merge with filters of the same shape
+// and serialize to binary and JSON so they can be persisted and combined
+// across processes.
+package probabilistic
+
+import (
+	"encoding/binary"
+	"errors"
+	"hash/fnv"
+	"math"
+)
+
+var (
+	// ErrIncompatible merging or decoding filters of a different shape
+	ErrIncompatible = errors.New("probabilistic: incompatible filter")
+
+	// ErrInvalidData serialized filter is corrupt or of another type
+	ErrInvalidData = errors.New("probabilistic: invalid data")
+)
+
+// Serialized filters start with a type byte and a version byte
+const (
+	typeBloom    byte = 'B'
+	typeCounting byte = 'C'
+	typeHLL      byte = 'H'
+	version      byte = 1
+)
+
+// maxHashes upper bound on the number of hash functions of a filter, more
+// only slows every operation without lowering the false positive rate
+const maxHashes = 64
+
+// hash128 stable 128 bit hash of b split in two, hashes must not depend on
+// the process so serialized filters stay valid
+func hash128(b []byte) (uint64, uint64) {
+	h := fnv.New128a()
+	h.Write(b)
+	sum := h.Sum(nil)
+	return mix(binary.BigEndian.Uint64(sum[:8])), mix(binary.BigEndian.Uint64(sum[8:]))
+}
+
+// mix murmur3 finalizer, fnv alone does not spread its bits well enough
+func mix(h uint64) uint64 {
+	h ^= h >> 33
+	h *= 0xff51afd7ed558ccd
+	h ^= h >> 33
+	h *= 0xc4ceb9fe1a85ec53
+	h ^= h >> 33
+	return h
+}
+
+// locations k bit positions for b in a filter of m bits, using double hashing
+func locations(b []byte, k, m uint64) []uint64 {
+	h1, h2 := hash128(b)
+
+	locs := make([]uint64, k)
+	for i := uint64(0); i < k; i++ {
+		locs[i] = (h1 + i*h2) % m
+	}
+	return locs
+}
+
+// EstimateParameters number of bits m and hash functions k for a filter
+// holding n items with false positive rate p
+func EstimateParameters(n uint64, p float64) (m uint64, k uint64) {
+	if n == 0 {
+		n = 1
+	}
+	if p <= 0 || p >= 1 {
+		p = 0.01
+	}
+
+	m = uint64(math.Ceil(-float64(n) * math.Log(p) / (math.Ln2 * math.Ln2)))
+	k = uint64(math.Round(float64(m) / float64(n) * math.Ln2))
+	return m, min(max(k, 1), maxHashes)
+}
+
+// header checks the type and version of serialized data, returning the rest
+func header(data []byte, kind byte) ([]byte, error) {
+	if len(data) < 2 || data[0] != kind || data[1] != version {
+		return nil, ErrInvalidData
+	}
+	return data[2:], nil
+}
@@ -0,0 +1,65 @@
+package probabilistic
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+
+	. "gopkg.in/check.v1"
+)
+
+type HyperLogLogSuite struct{}
+
+var _ = Suite(&HyperLogLogSuite{})
+
+// within relative error of expected
+func within(obtained uint64, expected int, tolerance float64) bool {
+	return math.Abs(float64(obtained)-float64(expected)) <= float64(expected)*tolerance
+}
+
+func (s *HyperLogLogSuite) Test_Count(c *C) {
+	for _, n := range []int{10, 1000, 100000} {
+		h := NewHyperLogLog(DefaultPrecision)
+		for i := 0; i < n; i++ {
+			h.AddString(fmt.Sprintf("id-%d", i))
+			h.AddString(fmt.Sprintf("id-%d", i))
+		}
+
+		count := h.Count()
+		c.Assert(within(count, n, 0.03), Equals, true, Commentf("n=%d count=%d", n, count))
+	}
+}
+
+func (s *HyperLogLogSuite) Test_Merge(c *C) {
+	a := NewHyperLogLog(DefaultPrecision)
+	b := NewHyperLogLog(DefaultPrecision)
+	for i := 0; i < 10000; i++ {
+		a.AddString(fmt.Sprintf("id-%d", i))
+		b.AddString(fmt.Sprintf("id-%d", i+5000))
+	}
+
+	c.Assert(a.Merge(b), IsNil)
+	c.Assert(within(a.Count(), 15000, 0.03), Equals, true)
+
+	c.Assert(a.Merge(NewHyperLogLog(10)), Equals, ErrIncompatible)
+}
+
+func (s *HyperLogLogSuite) Test_Serialize(c *C) {
+	h := NewHyperLogLog(10)
+	for i := 0; i < 500; i++ {
+		h.AddString(fmt.Sprintf("id-%d", i))
+	}
+
+	b, err := json.Marshal(h)
+	c.Assert(err, IsNil)
+
+	var decoded HyperLogLog
+	c.Assert(json.Unmarshal(b, &decoded), IsNil)
+	c.Assert(decoded.Precision(), Equals, uint8(10))
+	c.Assert(decoded.Count(), Equals, h.Count())
+}
+
+func (s *HyperLogLogSuite) Test_Precision(c *C) {
+	c.Assert(NewHyperLogLog(1).Precision(), Equals, uint8(MinPrecision))
+	c.Assert(NewHyperLogLog(30).Precision(), Equals, uint8(MaxPrecision))
+}
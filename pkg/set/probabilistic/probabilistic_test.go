@@ -0,0 +1,27 @@
+package probabilistic
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	. "gopkg.in/check.v1"
+)
+
+func Test(t *testing.T) {
+	TestingT(t)
+}
+
+// withK copy of a serialized filter with its hash count replaced by k
+func withK(data []byte, k uint64) []byte {
+	data = bytes.Clone(data)
+	binary.BigEndian.PutUint64(data[10:], k)
+	return data
+}
+
+// withM copy of a serialized filter with its size replaced by m
+func withM(data []byte, m uint64) []byte {
+	data = bytes.Clone(data)
+	binary.BigEndian.PutUint64(data[2:], m)
+	return data
+}
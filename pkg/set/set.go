@@ -4,8 +4,6 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
-	// "reflect"
-	// "strings"
 )
 
 type Set[T comparable] map[T]struct{}
@@ -221,46 +219,22 @@ func (set Set[T]) String() string {
 	return buf.String()
 }
 
-/*
-func (set StringSet) PowerSet() StringSet {
-	powSet := NewStringSet()
-	nullset := NewStringSet()
-	powSet.AddSet(nullset)
+// PowerSet every subset of the set including the empty set and the set
+// itself. Sets are not comparable so the subsets are returned as a slice,
+// there are 2^n of them.
+func (set Set[T]) PowerSet() []Set[T] {
+	powSet := []Set[T]{New[T]()}
 
-	for es := range set {
-		u := NewStringSet()
-		j := powSet.Iter()
-		for er := range j {
-			p := NewStringSet()
-
-			for ek := range er {
-				p.Add(ek)
-			}
-			p.Add(es)
-			u.AddSet(p)
+	for elem := range set {
+		for _, subset := range powSet {
+			p := subset.Clone()
+			p.Add(elem)
+			powSet = append(powSet, p)
 		}
-
-		powSet = powSet.Union(u)
 	}
 
 	return powSet
 }
-*/
-
-/*
-func (set StringSet) CartesianProduct(other StringSet) StringSet {
-	cartProduct := NewStringSet()
-
-	for i := range set {
-		for j := range other {
-			elem := orderedPair{first: i, second: j}
-			cartProduct.Add(elem)
-		}
-	}
-
-	return cartProduct
-}
-*/
 
 func (set Set[T]) ToSlice() []T {
 	keys := make([]T, 0, set.Cardinality())
@@ -270,27 +244,13 @@ func (set Set[T]) ToSlice() []T {
 	return keys
 }
 
-// MarshalJSON creates a JSON array from the set, it marshals all elements
+// MarshalJSON creates a JSON array from the set, elements are encoded with
+// encoding/json so keep their type
 func (set Set[T]) MarshalJSON() ([]byte, error) {
-	buf := &bytes.Buffer{}
-	buf.WriteRune('[')
-
-	var index int
-	for elem := range set {
-		if index > 0 {
-			buf.WriteRune(',')
-		}
-
-		fmt.Fprintf(buf, `"%v"`, elem)
-		index++
-	}
-
-	buf.WriteRune(']')
-	return buf.Bytes(), nil
+	return json.Marshal(set.ToSlice())
 }
 
-// UnmarshalJSON recreates a set from a JSON array, it only decodes
-// primitive types. Numbers are decoded as json.Number.
+// UnmarshalJSON recreates a set from a JSON array
 func (set *Set[T]) UnmarshalJSON(b []byte) error {
 	var list []T
 	if err := json.Unmarshal(b, &list); err != nil {
@@ -334,21 +294,3 @@ func newIterator[T comparable]() (*Iterator[T], chan<- T, <-chan struct{}) {
 		stop: stopChan,
 	}, itemChan, stopChan
 }
-
-type orderedPair struct {
-	first  string
-	second string
-}
-
-func (pair *orderedPair) Equal(other orderedPair) bool {
-	if pair.first == other.first &&
-		pair.second == other.second {
-		return true
-	}
-
-	return false
-}
-
-func (pair orderedPair) String() string {
-	return fmt.Sprintf("(%v, %v)", pair.first, pair.second)
-}
@@ -349,3 +349,21 @@ func (s *SetSuite) Test_JSONMarshaling(c *C) {
 
 	c.Assert(expected.Equals(actual), IsTrue)
 }
+
+func (s *SetSuite) Test_PowerSet(c *C) {
+	powSet := New(1, 2, 3).PowerSet()
+	c.Assert(powSet, HasLen, 8)
+
+	sizes := NewBag[int]()
+	for _, subset := range powSet {
+		sizes.Add(subset.Cardinality())
+	}
+	c.Assert(sizes.Equals(Bag[int]{0: 1, 1: 3, 2: 3, 3: 1}), IsTrue)
+}
+
+func (s *SetSuite) Test_CartesianProduct(c *C) {
+	product := CartesianProduct(New(1, 2), New("a", "b"))
+	c.Assert(product.Cardinality(), Equals, 4)
+	c.Assert(product.Contains(Pair[int, string]{1, "a"}, Pair[int, string]{2, "b"}), IsTrue)
+	c.Assert(Pair[int, string]{1, "a"}.String(), Equals, "(1, a)")
+}
@@ -0,0 +1,306 @@
+package streaming
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"os"
+	"sync/atomic"
+)
+
+// OverflowPolicy defines what a Buffer does when it is full
+type OverflowPolicy int
+
+const (
+	// Block stop reading input until there is room
+	Block OverflowPolicy = iota
+
+	// DropOldest discard the oldest buffered item to make room
+	DropOldest
+
+	// DropNewest discard the incoming item
+	DropNewest
+
+	// SpillToDisk write overflow to a temporary file, items must be gob
+	// encodable. Order is kept, spilled items are read back once there is
+	// room in memory.
+	SpillToDisk
+)
+
+func (t OverflowPolicy) String() string {
+	switch t {
+	case Block:
+		return "block"
+	case DropOldest:
+		return "drop-oldest"
+	case DropNewest:
+		return "drop-newest"
+	case SpillToDisk:
+		return "spill-to-disk"
+	}
+	return "unknown"
+}
+
+// Buffered output of a Buffer stage, its C channel is closed once the input
+// is closed and every buffered item has been delivered, or ctx is cancelled.
+// Errors receives spill file failures, with SpillToDisk it must be consumed
+// (see Group.Watch). Both channels close together.
+type Buffered[T any] struct {
+	C      <-chan T
+	Errors <-chan error
+
+	size    int
+	policy  OverflowPolicy
+	depth   atomic.Int64
+	dropped atomic.Int64
+	spilled atomic.Int64
+}
+
+// Depth number of items currently buffered, in memory and on disk
+func (t *Buffered[T]) Depth() int64 {
+	return t.depth.Load()
+}
+
+// Dropped number of items discarded by the overflow policy
+func (t *Buffered[T]) Dropped() int64 {
+	return t.dropped.Load()
+}
+
+// Spilled number of items written to disk
+func (t *Buffered[T]) Spilled() int64 {
+	return t.spilled.Load()
+}
+
+// Size capacity of the in memory buffer
+func (t *Buffered[T]) Size() int {
+	return t.size
+}
+
+// Policy overflow policy of the buffer
+func (t *Buffered[T]) Policy() OverflowPolicy {
+	return t.policy
+}
+
+// Buffer hold up to size items between a producer and a slow consumer,
+// applying policy when the buffer is full. Items that fail to spill, or to
+// be read back from disk, are lost and reported on Errors.
+func Buffer[T any](ctx context.Context, in <-chan T, size int, policy OverflowPolicy) *Buffered[T] {
+	if size < 1 {
+		size = 1
+	}
+
+	out := make(chan T)
+	errCh := make(chan error)
+	buffered := &Buffered[T]{
+		C:      out,
+		Errors: errCh,
+		size:   size,
+		policy: policy,
+	}
+
+	go buffered.loop(ctx, in, out, errCh)
+
+	return buffered
+}
+
+func (t *Buffered[T]) loop(ctx context.Context, in <-chan T, out chan<- T, errCh chan<- error) {
+	defer close(out)
+	defer close(errCh)
+
+	queue := make([]T, 0, t.size)
+
+	var spill *spillFile[T]
+	defer func() {
+		if spill != nil {
+			spill.Close()
+		}
+	}()
+
+	// fail returns false if ctx was cancelled before err was received
+	fail := func(err error) bool {
+		select {
+		case errCh <- err:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	for in != nil || len(queue) > 0 || (spill != nil && spill.pending > 0) {
+
+		// Refill memory from disk before accepting anything new to keep order
+		for spill != nil && spill.pending > 0 && len(queue) < t.size {
+			v, err := spill.Read()
+			if err != nil {
+				lost := spill.pending
+				t.depth.Add(-int64(lost))
+
+				// The decoder is lost in the file, start a new one on the
+				// next overflow
+				spill.Close()
+				spill = nil
+				if !fail(fmt.Errorf("reading spill file, %d items lost: %w", lost, err)) {
+					return
+				}
+				break
+			}
+			queue = append(queue, v)
+
+			// Start the file again once it has been drained so it does not
+			// grow without bound
+			if spill.pending == 0 {
+				if err := spill.reset(); err != nil && !fail(fmt.Errorf("resetting spill file: %w", err)) {
+					return
+				}
+			}
+		}
+
+		recvCh := in
+		if t.policy == Block && len(queue) >= t.size {
+			recvCh = nil
+		}
+
+		var sendCh chan<- T
+		var next T
+		if len(queue) > 0 {
+			sendCh = out
+			next = queue[0]
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+
+		case v, ok := <-recvCh:
+			if !ok {
+				in = nil
+				continue
+			}
+
+			if spill != nil && spill.pending > 0 {
+				if !t.toDisk(spill, v, fail) {
+					return
+				}
+				continue
+			}
+
+			if len(queue) < t.size {
+				queue = append(queue, v)
+				t.depth.Add(1)
+				continue
+			}
+
+			switch t.policy {
+			case DropOldest:
+				var zero T
+				queue[0] = zero
+				queue = append(queue[1:], v)
+				t.dropped.Add(1)
+
+			case DropNewest:
+				t.dropped.Add(1)
+
+			case SpillToDisk:
+				if spill == nil {
+					var err error
+					spill, err = newSpillFile[T]()
+					if err != nil {
+						if !fail(fmt.Errorf("creating spill file: %w", err)) {
+							return
+						}
+						continue
+					}
+				}
+				if !t.toDisk(spill, v, fail) {
+					return
+				}
+			}
+
+		case sendCh <- next:
+			var zero T
+			queue[0] = zero
+			queue = queue[1:]
+			t.depth.Add(-1)
+		}
+	}
+}
+
+// toDisk spill v, a failure is reported with fail. Returns false if ctx was
+// cancelled.
+func (t *Buffered[T]) toDisk(spill *spillFile[T], v T, fail func(error) bool) bool {
+	if err := spill.Write(v); err != nil {
+		return fail(fmt.Errorf("writing spill file: %w", err))
+	}
+	t.spilled.Add(1)
+	t.depth.Add(1)
+	return true
+}
+
+// spillFile FIFO of gob encoded items backed by a temporary file
+type spillFile[T any] struct {
+	writer  *os.File
+	reader  *os.File
+	encoder *gob.Encoder
+	decoder *gob.Decoder
+	pending int
+}
+
+func newSpillFile[T any]() (*spillFile[T], error) {
+	writer, err := os.CreateTemp("", "streaming-spill-*")
+	if err != nil {
+		return nil, err
+	}
+
+	reader, err := os.Open(writer.Name())
+	if err != nil {
+		writer.Close()
+		os.Remove(writer.Name())
+		return nil, err
+	}
+
+	return &spillFile[T]{
+		writer:  writer,
+		reader:  reader,
+		encoder: gob.NewEncoder(writer),
+		decoder: gob.NewDecoder(reader),
+	}, nil
+}
+
+func (t *spillFile[T]) Write(v T) error {
+	if err := t.encoder.Encode(&v); err != nil {
+		return err
+	}
+	t.pending++
+	return nil
+}
+
+func (t *spillFile[T]) Read() (T, error) {
+	var v T
+	if err := t.decoder.Decode(&v); err != nil {
+		return v, err
+	}
+	t.pending--
+	return v, nil
+}
+
+func (t *spillFile[T]) reset() error {
+	if err := t.writer.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := t.writer.Seek(0, 0); err != nil {
+		return err
+	}
+	if _, err := t.reader.Seek(0, 0); err != nil {
+		return err
+	}
+
+	t.encoder = gob.NewEncoder(t.writer)
+	t.decoder = gob.NewDecoder(t.reader)
+	return nil
+}
+
+func (t *spillFile[T]) Close() error {
+	t.reader.Close()
+	t.writer.Close()
+	return os.Remove(t.writer.Name())
+}
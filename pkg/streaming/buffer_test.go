@@ -0,0 +1,190 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type BufferSuite struct{}
+
+var _ = Suite(&BufferSuite{})
+
+// fill sends items into an unconsumed buffer and waits for it to settle
+func fill[T any](in chan<- T, items ...T) {
+	for _, v := range items {
+		in <- v
+	}
+	time.Sleep(10 * time.Millisecond)
+}
+
+func (s *BufferSuite) Test_Buffer_Block(c *C) {
+	in := make(chan int)
+	buffered := Buffer(context.Background(), in, 3, Block)
+
+	fill(in, 1, 2, 3)
+	c.Assert(buffered.Depth(), Equals, int64(3))
+
+	select {
+	case in <- 4:
+		c.Fatal("buffer should block when full")
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	go func() {
+		in <- 4
+		close(in)
+	}()
+
+	c.Assert(Gather(buffered.C), DeepEquals, []int{1, 2, 3, 4})
+	c.Assert(buffered.Dropped(), Equals, int64(0))
+	c.Assert(buffered.Depth(), Equals, int64(0))
+}
+
+func (s *BufferSuite) Test_Buffer_DropOldest(c *C) {
+	in := make(chan int)
+	buffered := Buffer(context.Background(), in, 3, DropOldest)
+
+	fill(in, 1, 2, 3, 4, 5)
+	close(in)
+
+	c.Assert(Gather(buffered.C), DeepEquals, []int{3, 4, 5})
+	c.Assert(buffered.Dropped(), Equals, int64(2))
+}
+
+func (s *BufferSuite) Test_Buffer_DropNewest(c *C) {
+	in := make(chan int)
+	buffered := Buffer(context.Background(), in, 3, DropNewest)
+
+	fill(in, 1, 2, 3, 4, 5)
+	close(in)
+
+	c.Assert(Gather(buffered.C), DeepEquals, []int{1, 2, 3})
+	c.Assert(buffered.Dropped(), Equals, int64(2))
+}
+
+func (s *BufferSuite) Test_Buffer_SpillToDisk(c *C) {
+	type record struct {
+		ID   int
+		Name string
+	}
+
+	in := make(chan record)
+	buffered := Buffer(context.Background(), in, 2, SpillToDisk)
+
+	var expected []record
+	for i := 0; i < 10; i++ {
+		expected = append(expected, record{ID: i, Name: "name"})
+	}
+
+	fill(in, expected...)
+	c.Assert(buffered.Depth(), Equals, int64(10))
+	c.Assert(buffered.Spilled(), Equals, int64(8))
+
+	// Read part of the buffer, then add more while items remain on disk
+	c.Assert(<-buffered.C, Equals, expected[0])
+	c.Assert(<-buffered.C, Equals, expected[1])
+
+	more := []record{{ID: 10}, {ID: 11}}
+	fill(in, more...)
+	close(in)
+
+	c.Assert(Gather(buffered.C), DeepEquals, append(expected[2:], more...))
+	c.Assert(buffered.Dropped(), Equals, int64(0))
+	c.Assert(buffered.Depth(), Equals, int64(0))
+}
+
+func (s *BufferSuite) Test_Buffer_SpillToDisk_Reuse(c *C) {
+	in := make(chan int)
+	buffered := Buffer(context.Background(), in, 1, SpillToDisk)
+
+	for round := 0; round < 3; round++ {
+		fill(in, 1, 2, 3)
+		c.Assert(<-buffered.C, Equals, 1)
+		c.Assert(<-buffered.C, Equals, 2)
+		c.Assert(<-buffered.C, Equals, 3)
+	}
+
+	close(in)
+	Consume(buffered.C)
+	c.Assert(buffered.Spilled(), Equals, int64(6))
+}
+
+func (s *BufferSuite) Test_Buffer_SpillError(c *C) {
+	// Channels cannot be gob encoded so every spill fails
+	in := make(chan chan int)
+	buffered := Buffer(context.Background(), in, 1, SpillToDisk)
+
+	errCh := make(chan []error)
+	go func() {
+		errCh <- Gather(buffered.Errors)
+	}()
+
+	for i := 0; i < 3; i++ {
+		in <- make(chan int)
+	}
+	close(in)
+
+	c.Assert(Gather(buffered.C), HasLen, 1)
+
+	errs := <-errCh
+	c.Assert(errs, HasLen, 2)
+	c.Assert(errs[0], ErrorMatches, "writing spill file: .*")
+	c.Assert(buffered.Spilled(), Equals, int64(0))
+	c.Assert(buffered.Depth(), Equals, int64(0))
+}
+
+// flaky gob encodes every value but fails to decode 2
+type flaky int
+
+func (t flaky) GobEncode() ([]byte, error) {
+	return []byte{byte(t)}, nil
+}
+
+func (t *flaky) GobDecode(b []byte) error {
+	if b[0] == 2 {
+		return errors.New("corrupt")
+	}
+	*t = flaky(b[0])
+	return nil
+}
+
+func (s *BufferSuite) Test_Buffer_SpillReadError(c *C) {
+	in := make(chan flaky)
+	buffered := Buffer(context.Background(), in, 1, SpillToDisk)
+
+	errCh := make(chan []error)
+	go func() {
+		errCh <- Gather(buffered.Errors)
+	}()
+
+	// 2, 3 and 4 are spilled, reading 2 back fails and loses all three
+	fill(in, 1, 2, 3, 4)
+	c.Assert(<-buffered.C, Equals, flaky(1))
+
+	// Later spills go to a new file and are not lost too
+	fill(in, 5, 6, 7)
+	close(in)
+	c.Assert(Gather(buffered.C), DeepEquals, []flaky{5, 6, 7})
+
+	errs := <-errCh
+	c.Assert(errs, HasLen, 1)
+	c.Assert(errs[0], ErrorMatches, "reading spill file, 3 items lost: .*corrupt")
+	c.Assert(buffered.Spilled(), Equals, int64(5))
+	c.Assert(buffered.Depth(), Equals, int64(0))
+}
+
+func (s *BufferSuite) Test_Buffer_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan int)
+	buffered := Buffer(ctx, in, 3, Block)
+	fill(in, 1, 2)
+
+	// The reader went away, cancelling closes the output
+	cancel()
+	Consume(buffered.C)
+	Consume(buffered.Errors)
+}
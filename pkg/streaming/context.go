@@ -0,0 +1,444 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Envelope carrying either the value produced for an item or the error
+// that prevented it
+type Envelope[T any] struct {
+	Value T
+	Err   error
+}
+
+// ItemError wraps an error returned by a stage function together with the
+// item that caused it
+type ItemError[T any] struct {
+	Item T
+	Err  error
+}
+
+func (t *ItemError[T]) Error() string {
+	return fmt.Sprintf("item %v: %v", t.Item, t.Err)
+}
+
+func (t *ItemError[T]) Unwrap() error {
+	return t.Err
+}
+
+// Group ties the stages of a pipeline to a shared context. The first error
+// reported to the group cancels the context, stopping every stage built on it.
+//
+//	g, ctx := streaming.NewGroup(context.Background())
+//	out, errCh := streaming.MorphContext(ctx, in, parse)
+//	g.Watch(errCh)
+//	rows := streaming.Gather(out)
+//	err := g.Wait()
+type Group struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+	wg     sync.WaitGroup
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewGroup create a group and the context its stages should run under
+func NewGroup(parent context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancelCause(parent)
+	return &Group{
+		ctx:    ctx,
+		cancel: cancel,
+	}, ctx
+}
+
+// Fail record err as the group error and cancel the pipeline, only the first
+// error is kept
+func (t *Group) Fail(err error) {
+	if err == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.err == nil {
+		t.err = err
+		t.cancel(err)
+	}
+}
+
+// Watch consume an error channel, failing the group on the first error
+func (t *Group) Watch(in ...<-chan error) {
+	for _, ch := range in {
+		if ch == nil {
+			continue
+		}
+
+		t.wg.Add(1)
+		go func(ch <-chan error) {
+			defer t.wg.Done()
+
+			for err := range ch {
+				t.Fail(err)
+			}
+		}(ch)
+	}
+}
+
+// Go run fn as part of the group, a returned error fails the group
+func (t *Group) Go(fn func(ctx context.Context) error) {
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		t.Fail(fn(t.ctx))
+	}()
+}
+
+// Wait for all watched channels and functions to finish, returns the first
+// error reported
+func (t *Group) Wait() error {
+	t.wg.Wait()
+	t.cancel(nil)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.err
+}
+
+// Err returns the first error reported, nil if the group has not failed
+func (t *Group) Err() error {
+	select {
+	case <-t.ctx.Done():
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		return t.err
+	default:
+		return nil
+	}
+}
+
+// DoneContext context cancelled once done is closed, for calling context
+// based stages from code built on done channels. cancel releases the context
+// and must be called.
+func DoneContext(done <-chan struct{}) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-done:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
+}
+
+// GenerateContext emits v until exhausted or ctx is cancelled
+func GenerateContext[T any](ctx context.Context, v ...T) <-chan T {
+	out := make(chan T)
+	go func() {
+		defer close(out)
+		for _, n := range v {
+			select {
+			case out <- n:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+// ApplyContext apply fn to every item, errors are sent on the error channel
+// as *ItemError and the failing item is dropped. The error channel must be
+// consumed (see Group.Watch).
+func ApplyContext[T any](ctx context.Context, in <-chan T, fn func(context.Context, T) (T, error)) (<-chan T, <-chan error) {
+	return MorphContext[T, T](ctx, in, fn)
+}
+
+// MorphContext convert every item with fn, errors are sent on the error
+// channel as *ItemError and the failing item is dropped. The error channel
+// must be consumed (see Group.Watch).
+func MorphContext[I any, O any](ctx context.Context, in <-chan I, fn func(context.Context, I) (O, error)) (<-chan O, <-chan error) {
+	out := make(chan O)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(out)
+		defer close(errCh)
+
+		for {
+			var v I
+			var ok bool
+
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			o, err := fn(ctx, v)
+			if err != nil {
+				select {
+				case errCh <- &ItemError[I]{Item: v, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			select {
+			case out <- o:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, errCh
+}
+
+// MorphEnvelope convert every item with fn, emitting an Envelope for
+// every item whether it succeeded or not
+func MorphEnvelope[I any, O any](ctx context.Context, in <-chan I, fn func(context.Context, I) (O, error)) <-chan Envelope[O] {
+	out := make(chan Envelope[O])
+
+	go func() {
+		defer close(out)
+
+		for {
+			var v I
+			var ok bool
+
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			o, err := fn(ctx, v)
+			if err != nil {
+				err = &ItemError[I]{Item: v, Err: err}
+			}
+
+			select {
+			case out <- Envelope[O]{Value: o, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// SplitEnvelope separate an Envelope stream into values and errors. The error
+// channel must be consumed.
+func SplitEnvelope[T any](ctx context.Context, in <-chan Envelope[T]) (<-chan T, <-chan error) {
+	out := make(chan T)
+	errCh := make(chan error)
+
+	go func() {
+		defer close(out)
+		defer close(errCh)
+
+		for {
+			var r Envelope[T]
+			var ok bool
+
+			select {
+			case r, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			if r.Err != nil {
+				select {
+				case errCh <- r.Err:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			select {
+			case out <- r.Value:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, errCh
+}
+
+// MergeContext merge channels until they are all closed or ctx is cancelled
+func MergeContext[T any](ctx context.Context, in ...<-chan T) <-chan T {
+	out := make(chan T)
+
+	var wg sync.WaitGroup
+
+	merge := func(ch <-chan T) {
+		defer wg.Done()
+
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	for _, ch := range in {
+		if ch == nil {
+			continue
+		}
+
+		wg.Add(1)
+		go merge(ch)
+	}
+
+	go func() {
+		defer close(out)
+		wg.Wait()
+	}()
+
+	return out
+}
+
+// FanOutContext broadcast every item to num channels until in is closed or
+// ctx is cancelled
+func FanOutContext[T any](ctx context.Context, in <-chan T, num int) []<-chan T {
+	streams := make([]chan T, num)
+	outs := make([]<-chan T, num)
+	for i := range streams {
+		streams[i] = make(chan T)
+		outs[i] = streams[i]
+	}
+
+	go func() {
+		defer func() {
+			for i := range streams {
+				close(streams[i])
+			}
+		}()
+
+		for {
+			select {
+			case val, ok := <-in:
+				if !ok {
+					return
+				}
+
+				for _, ch := range streams {
+					select {
+					case ch <- val:
+					case <-ctx.Done():
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return outs
+}
+
+// BatchContext group items into batches of batchSize, partial batches are
+// emitted after timeout. When in closes the final partial batch is emitted,
+// when ctx is cancelled it is discarded.
+func BatchContext[T any](ctx context.Context, in <-chan T, batchSize int, timeout time.Duration) <-chan []T {
+	out := make(chan []T)
+
+	go func() {
+		defer close(out)
+
+		batch := make([]T, 0, batchSize)
+		timer := time.NewTimer(timeout)
+		defer timer.Stop()
+
+		flush := func() bool {
+			if len(batch) == 0 {
+				return true
+			}
+
+			select {
+			case out <- batch:
+				batch = make([]T, 0, batchSize)
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case item, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+
+				batch = append(batch, item)
+				if len(batch) == batchSize {
+					if !flush() {
+						return
+					}
+					timer.Reset(timeout)
+				}
+
+			case <-timer.C:
+				if !flush() {
+					return
+				}
+				timer.Reset(timeout)
+			}
+		}
+	}()
+
+	return out
+}
+
+// GatherContext collect items until in is closed or ctx is cancelled,
+// returning the context error if it was cancelled
+func GatherContext[T any](ctx context.Context, in <-chan T) ([]T, error) {
+	arr := make([]T, 0, 10)
+	for {
+		select {
+		case val, ok := <-in:
+			if !ok {
+				return arr, nil
+			}
+			arr = append(arr, val)
+		case <-ctx.Done():
+			return arr, context.Cause(ctx)
+		}
+	}
+}
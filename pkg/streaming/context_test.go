@@ -0,0 +1,158 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ContextSuite struct{}
+
+var _ = Suite(&ContextSuite{})
+
+func (s *ContextSuite) Test_MorphContext(c *C) {
+	g, ctx := NewGroup(context.Background())
+
+	in := GenerateContext(ctx, "1", "2", "3")
+	out, errCh := MorphContext(ctx, in, func(_ context.Context, v string) (int, error) {
+		return strconv.Atoi(v)
+	})
+	g.Watch(errCh)
+
+	c.Assert(Gather(out), DeepEquals, []int{1, 2, 3})
+	c.Assert(g.Wait(), IsNil)
+}
+
+func (s *ContextSuite) Test_MorphContext_Fail(c *C) {
+	g, ctx := NewGroup(context.Background())
+
+	in := GenerateContext(ctx, "1", "x", "3", "4", "5")
+	out, errCh := MorphContext(ctx, in, func(_ context.Context, v string) (int, error) {
+		return strconv.Atoi(v)
+	})
+	g.Watch(errCh)
+
+	Consume(out)
+
+	err := g.Wait()
+	c.Assert(err, NotNil)
+
+	var itemErr *ItemError[string]
+	c.Assert(errors.As(err, &itemErr), Equals, true)
+	c.Assert(itemErr.Item, Equals, "x")
+	c.Assert(ctx.Err(), NotNil)
+}
+
+func (s *ContextSuite) Test_MorphEnvelope(c *C) {
+	ctx := context.Background()
+
+	in := GenerateContext(ctx, "1", "x", "3")
+	out := MorphEnvelope(ctx, in, func(_ context.Context, v string) (int, error) {
+		return strconv.Atoi(v)
+	})
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 3)
+	c.Assert(results[0].Value, Equals, 1)
+	c.Assert(results[1].Err, NotNil)
+	c.Assert(results[2].Value, Equals, 3)
+
+	values, errCh := SplitEnvelope(ctx, GenerateContext(ctx, results...))
+	go Consume(errCh)
+	c.Assert(Gather(values), DeepEquals, []int{1, 3})
+}
+
+func (s *ContextSuite) Test_Group_Go(c *C) {
+	g, ctx := NewGroup(context.Background())
+	failure := errors.New("failure")
+
+	g.Go(func(ctx context.Context) error {
+		return failure
+	})
+	g.Go(func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	c.Assert(g.Wait(), Equals, failure)
+	c.Assert(context.Cause(ctx), Equals, failure)
+}
+
+func (s *ContextSuite) Test_DoneContext(c *C) {
+	done := make(chan struct{})
+	ctx, cancel := DoneContext(done)
+	defer cancel()
+
+	c.Assert(ctx.Err(), IsNil)
+	close(done)
+	<-ctx.Done()
+	c.Assert(ctx.Err(), Equals, context.Canceled)
+}
+
+func (s *ContextSuite) Test_MergeContext(c *C) {
+	ctx := context.Background()
+
+	out := MergeContext(ctx, GenerateContext(ctx, 1, 2, 3), GenerateContext(ctx, 4, 5, 6))
+	c.Assert(Gather(out), HasLen, 6)
+}
+
+func (s *ContextSuite) Test_MergeContext_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan int)
+	out := MergeContext(ctx, in)
+	cancel()
+
+	for _ = range out {
+		c.Fail()
+	}
+}
+
+func (s *ContextSuite) Test_FanOutContext(c *C) {
+	ctx := context.Background()
+
+	streams := FanOutContext(ctx, GenerateContext(ctx, 1, 2, 3), 2)
+	c.Assert(streams, HasLen, 2)
+
+	done := make(chan []int)
+	for _, ch := range streams {
+		go func(ch <-chan int) {
+			done <- Gather(ch)
+		}(ch)
+	}
+
+	c.Assert(<-done, DeepEquals, []int{1, 2, 3})
+	c.Assert(<-done, DeepEquals, []int{1, 2, 3})
+}
+
+func (s *ContextSuite) Test_BatchContext(c *C) {
+	ctx := context.Background()
+
+	out := BatchContext(ctx, GenerateContext(ctx, 1, 2, 3, 4, 5), 2, time.Second)
+	c.Assert(Gather(out), DeepEquals, [][]int{{1, 2}, {3, 4}, {5}})
+}
+
+func (s *ContextSuite) Test_BatchContext_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan int)
+	out := BatchContext(ctx, in, 2, time.Second)
+
+	in <- 1
+	cancel()
+
+	for _ = range out {
+		c.Fail()
+	}
+}
+
+func (s *ContextSuite) Test_GatherContext_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := GatherContext(ctx, make(chan int))
+	c.Assert(err, Equals, context.Canceled)
+}
@@ -0,0 +1,157 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrDrainTimeout the drain deadline passed before in flight items flushed
+var ErrDrainTimeout = errors.New("drain deadline exceeded")
+
+// Drainer coordinates a graceful shutdown in three steps: sources stop
+// accepting input, items already in flight flush through the remaining
+// stages, then everything closes. Sources gate their input on Stopping and
+// close their output when it fires, downstream stages see a closed input and
+// flush as they normally would. If the flush takes longer than the deadline
+// the context is cancelled and the stages are abandoned.
+//
+//	d, ctx := streaming.NewDrainer(context.Background())
+//	rows := streaming.Gate(ctx, d.Stopping(), source)
+//	d.Go(func(ctx context.Context) error { return load(ctx, rows) })
+//	d.DrainOn(utils.Shutdown(), 30*time.Second)
+//	err := d.Wait()
+type Drainer struct {
+	group *Group
+
+	stopCh chan struct{}
+	once   sync.Once
+}
+
+// NewDrainer create a drainer and the context its stages should run under,
+// the context is only cancelled by a failure or a missed drain deadline
+func NewDrainer(parent context.Context) (*Drainer, context.Context) {
+	group, ctx := NewGroup(parent)
+	return &Drainer{
+		group:  group,
+		stopCh: make(chan struct{}),
+	}, ctx
+}
+
+// Stopping closed once a drain has started, sources should stop reading
+func (t *Drainer) Stopping() <-chan struct{} {
+	return t.stopCh
+}
+
+// Stop tell sources to stop accepting input without waiting for the drain
+func (t *Drainer) Stop() {
+	t.once.Do(func() {
+		close(t.stopCh)
+	})
+}
+
+// Go run fn as part of the drain, a returned error cancels every stage
+func (t *Drainer) Go(fn func(ctx context.Context) error) {
+	t.group.Go(fn)
+}
+
+// Fail cancel every stage with err
+func (t *Drainer) Fail(err error) {
+	t.group.Fail(err)
+}
+
+// Wait for every function to finish, returns the first error
+func (t *Drainer) Wait() error {
+	return t.group.Wait()
+}
+
+// Drain stop sources and wait up to timeout for in flight items to flush.
+// Once the deadline passes the stages are cancelled with ErrDrainTimeout. A
+// timeout of 0 waits indefinitely.
+func (t *Drainer) Drain(timeout time.Duration) error {
+	t.Stop()
+	return drainWait(t.group, timeout)
+}
+
+// DrainOn start a drain when signal closes, eg utils.Shutdown(). The
+// returned channel receives the result of the drain.
+func (t *Drainer) DrainOn(signal <-chan struct{}, timeout time.Duration) <-chan error {
+	errCh := make(chan error, 1)
+
+	go func() {
+		defer close(errCh)
+
+		select {
+		case <-signal:
+		case <-t.stopCh:
+		}
+		errCh <- t.Drain(timeout)
+	}()
+
+	return errCh
+}
+
+// drainWait wait for group, failing it with ErrDrainTimeout if it is still
+// running after timeout
+func drainWait(group *Group, timeout time.Duration) error {
+	if timeout <= 0 {
+		return group.Wait()
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- group.Wait()
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-timer.C:
+		group.Fail(ErrDrainTimeout)
+		return <-errCh
+	}
+}
+
+// Gate forward in until it closes or stop is closed, then close the output.
+// Placed after a source it turns a stop signal into a closed input, which
+// every downstream stage already knows how to flush. An item already read
+// from in is still delivered unless ctx is cancelled first, items the source
+// has not handed over yet are left unread.
+func Gate[T any](ctx context.Context, stop <-chan struct{}, in <-chan T) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	return out
+}
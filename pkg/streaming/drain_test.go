@@ -0,0 +1,174 @@
+package streaming
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type DrainSuite struct{}
+
+var _ = Suite(&DrainSuite{})
+
+// endless emits increasing integers until ctx is cancelled
+func endless(ctx context.Context) <-chan int {
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for i := 0; ; i++ {
+			select {
+			case out <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out
+}
+
+func (s *DrainSuite) Test_Gate(c *C) {
+	stop := make(chan struct{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	out := Gate(ctx, stop, endless(ctx))
+	c.Assert(<-out, Equals, 0)
+	c.Assert(<-out, Equals, 1)
+
+	// At most the item Gate had already read is delivered after stop
+	close(stop)
+	rest := Gather(out)
+	c.Assert(len(rest) <= 1, Equals, true)
+	if len(rest) == 1 {
+		c.Assert(rest[0], Equals, 2)
+	}
+}
+
+func (s *DrainSuite) Test_Gate_Delivers(c *C) {
+	stop := make(chan struct{})
+	in := make(chan int)
+
+	out := Gate(context.Background(), stop, in)
+	in <- 1
+
+	// The item was handed over before stop, it must not be lost
+	close(stop)
+	c.Assert(Gather(out), DeepEquals, []int{1})
+}
+
+func (s *DrainSuite) Test_Gate_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	in := make(chan int)
+
+	out := Gate(ctx, make(chan struct{}), in)
+	in <- 1
+
+	// Gate holds the item with nobody reading, cancelling releases it
+	cancel()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for range out {
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		c.Fatal("gate blocked after cancel")
+	}
+}
+
+func (s *DrainSuite) Test_Gate_Closed(c *C) {
+	out := Gate(context.Background(), make(chan struct{}), Generate(nil, 1, 2, 3))
+	c.Assert(Gather(out), DeepEquals, []int{1, 2, 3})
+}
+
+func (s *DrainSuite) Test_Drain(c *C) {
+	d, ctx := NewDrainer(context.Background())
+
+	source, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := BatchContext(ctx, Gate(ctx, d.Stopping(), endless(source)), 3, time.Hour)
+
+	var mu sync.Mutex
+	var items []int
+	d.Go(func(ctx context.Context) error {
+		for batch := range batches {
+			mu.Lock()
+			items = append(items, batch...)
+			mu.Unlock()
+		}
+		return nil
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	c.Assert(d.Drain(time.Second), IsNil)
+
+	// The partial batch held when the source stopped is flushed
+	c.Assert(len(items) > 0, Equals, true)
+	for i, v := range items {
+		c.Assert(v, Equals, i)
+	}
+}
+
+func (s *DrainSuite) Test_Drain_Timeout(c *C) {
+	d, _ := NewDrainer(context.Background())
+
+	started := make(chan struct{})
+	d.Go(func(ctx context.Context) error {
+		close(started)
+		<-ctx.Done()
+		return context.Cause(ctx)
+	})
+	<-started
+
+	c.Assert(d.Drain(10*time.Millisecond), Equals, ErrDrainTimeout)
+}
+
+func (s *DrainSuite) Test_DrainOn(c *C) {
+	d, _ := NewDrainer(context.Background())
+
+	signal := make(chan struct{})
+	errCh := d.DrainOn(signal, time.Second)
+
+	d.Go(func(context.Context) error {
+		<-d.Stopping()
+		return nil
+	})
+
+	close(signal)
+	c.Assert(<-errCh, IsNil)
+}
+
+func (s *DrainSuite) Test_Pipeline_Drain(c *C) {
+	p := NewPipeline(context.Background(), "drain")
+
+	source, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	batches := Via(From(p, "source", endless(source)), "batch", func(ctx context.Context, in <-chan int) <-chan []int {
+		return BatchContext(ctx, in, 4, time.Hour)
+	})
+
+	var mu sync.Mutex
+	var total int64
+	Sink(batches, "sink", func(_ context.Context, batch []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		total += int64(len(batch))
+		return nil
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	c.Assert(p.Drain(time.Second), IsNil)
+
+	// Everything read by the source reached the sink
+	c.Assert(total, Equals, p.Stage("source").Stats().In)
+	c.Assert(p.Stage("sink").Stats().Done, Equals, true)
+}
@@ -0,0 +1,223 @@
+//go:build go1.23
+
+package streaming
+
+import (
+	"context"
+	"iter"
+)
+
+// Pull-style equivalents of the channel stages. They run on the caller's
+// goroutine so are much cheaper for in-memory transforms, and stopping a
+// range loop early stops every stage without leaking goroutines.
+
+// GenerateSeq iterate over v
+func GenerateSeq[T any](v ...T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, n := range v {
+			if !yield(n) {
+				return
+			}
+		}
+	}
+}
+
+// GatherSeq collect every item of seq
+func GatherSeq[T any](seq iter.Seq[T]) []T {
+	arr := make([]T, 0, 10)
+	for v := range seq {
+		arr = append(arr, v)
+	}
+	return arr
+}
+
+// ApplySeq apply fn to every item
+func ApplySeq[T any](seq iter.Seq[T], fn func(T) T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if !yield(fn(v)) {
+				return
+			}
+		}
+	}
+}
+
+// MorphSeq convert every item with fn, yielding the value and error of each
+// item so the caller decides whether to stop on failure
+func MorphSeq[I any, O any](seq iter.Seq[I], fn func(I) (O, error)) iter.Seq2[O, error] {
+	return func(yield func(O, error) bool) {
+		for v := range seq {
+			o, err := fn(v)
+			if err != nil {
+				err = &ItemError[I]{Item: v, Err: err}
+			}
+
+			if !yield(o, err) {
+				return
+			}
+		}
+	}
+}
+
+// FilterSeq yield only the items fn returns true for
+func FilterSeq[T any](seq iter.Seq[T], fn func(T) bool) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range seq {
+			if fn(v) && !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// OkSeq drop items with an error, yielding only successful values
+func OkSeq[T any](seq iter.Seq2[T, error]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v, err := range seq {
+			if err != nil {
+				continue
+			}
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// BatchSeq group items into slices of batchSize, the last batch may be
+// smaller. Each batch is a new slice that is safe to keep.
+func BatchSeq[T any](seq iter.Seq[T], batchSize int) iter.Seq[[]T] {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+
+	return func(yield func([]T) bool) {
+		batch := make([]T, 0, batchSize)
+		for v := range seq {
+			batch = append(batch, v)
+			if len(batch) == batchSize {
+				if !yield(batch) {
+					return
+				}
+				batch = make([]T, 0, batchSize)
+			}
+		}
+
+		if len(batch) > 0 {
+			yield(batch)
+		}
+	}
+}
+
+// ConcatSeq iterate over each seq in turn
+func ConcatSeq[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for _, seq := range seqs {
+			for v := range seq {
+				if !yield(v) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// TakeSeq yield at most n items
+func TakeSeq[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		if n <= 0 {
+			return
+		}
+
+		var i int
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+			i++
+			if i >= n {
+				return
+			}
+		}
+	}
+}
+
+// FromChan iterate over a channel until it is closed. Stopping early leaves
+// the channel unread, the producer must be stopped separately (eg by
+// cancelling its context).
+func FromChan[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FromChanContext iterate over a channel until it is closed or ctx is
+// cancelled
+func FromChanContext[T any](ctx context.Context, ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok || !yield(v) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// FromEnvelopes iterate over an Envelope channel as value, error pairs
+func FromEnvelopes[T any](ch <-chan Envelope[T]) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for e := range ch {
+			if !yield(e.Value, e.Err) {
+				return
+			}
+		}
+	}
+}
+
+// ToChan push seq into a channel from a new goroutine, the goroutine exits
+// when seq is exhausted or ctx is cancelled
+func ToChan[T any](ctx context.Context, seq iter.Seq[T]) <-chan T {
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for v := range seq {
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// ToEnvelopes push a value, error sequence into an Envelope channel
+func ToEnvelopes[T any](ctx context.Context, seq iter.Seq2[T, error]) <-chan Envelope[T] {
+	out := make(chan Envelope[T])
+
+	go func() {
+		defer close(out)
+
+		for v, err := range seq {
+			select {
+			case out <- Envelope[T]{Value: v, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
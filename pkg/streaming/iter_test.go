@@ -0,0 +1,119 @@
+//go:build go1.23
+
+package streaming
+
+import (
+	"context"
+	"errors"
+	"runtime"
+	"strconv"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type IterSuite struct{}
+
+var _ = Suite(&IterSuite{})
+
+func (s *IterSuite) Test_ApplySeq(c *C) {
+	seq := ApplySeq(GenerateSeq(1, 2, 3), func(i int) int {
+		return i * i
+	})
+	c.Assert(GatherSeq(seq), DeepEquals, []int{1, 4, 9})
+}
+
+func (s *IterSuite) Test_FilterSeq(c *C) {
+	seq := FilterSeq(GenerateSeq(sequence(10)...), func(i int) bool {
+		return i%3 == 0
+	})
+	c.Assert(GatherSeq(seq), DeepEquals, []int{0, 3, 6, 9})
+}
+
+func (s *IterSuite) Test_MorphSeq(c *C) {
+	seq := MorphSeq(GenerateSeq("1", "x", "3"), strconv.Atoi)
+
+	var values []int
+	var errs []error
+	for v, err := range seq {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		values = append(values, v)
+	}
+
+	c.Assert(values, DeepEquals, []int{1, 3})
+	c.Assert(errs, HasLen, 1)
+
+	var itemErr *ItemError[string]
+	c.Assert(errors.As(errs[0], &itemErr), Equals, true)
+	c.Assert(itemErr.Item, Equals, "x")
+
+	c.Assert(GatherSeq(OkSeq(seq)), DeepEquals, []int{1, 3})
+}
+
+func (s *IterSuite) Test_BatchSeq(c *C) {
+	seq := BatchSeq(GenerateSeq(1, 2, 3, 4, 5), 2)
+	c.Assert(GatherSeq(seq), DeepEquals, [][]int{{1, 2}, {3, 4}, {5}})
+}
+
+func (s *IterSuite) Test_ConcatSeq_TakeSeq(c *C) {
+	seq := ConcatSeq(GenerateSeq(1, 2), GenerateSeq(3, 4))
+	c.Assert(GatherSeq(seq), DeepEquals, []int{1, 2, 3, 4})
+	c.Assert(GatherSeq(TakeSeq(seq, 3)), DeepEquals, []int{1, 2, 3})
+	c.Assert(GatherSeq(TakeSeq(seq, 0)), HasLen, 0)
+}
+
+func (s *IterSuite) Test_FromChan(c *C) {
+	seq := FromChan(Generate(nil, 1, 2, 3))
+	c.Assert(GatherSeq(seq), DeepEquals, []int{1, 2, 3})
+}
+
+func (s *IterSuite) Test_FromChanContext(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c.Assert(GatherSeq(FromChanContext(ctx, make(chan int))), HasLen, 0)
+}
+
+func (s *IterSuite) Test_ToChan(c *C) {
+	ctx := context.Background()
+	c.Assert(Gather(ToChan(ctx, GenerateSeq(1, 2, 3))), DeepEquals, []int{1, 2, 3})
+}
+
+func (s *IterSuite) Test_ToChan_Cancel(c *C) {
+	before := runtime.NumGoroutine()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	out := ToChan(ctx, GenerateSeq(sequence(100)...))
+	<-out
+	cancel()
+
+	for _ = range out {
+	}
+
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	c.Assert(runtime.NumGoroutine() <= before, Equals, true)
+}
+
+func (s *IterSuite) Test_Envelopes(c *C) {
+	ctx := context.Background()
+
+	envelopes := ToEnvelopes(ctx, MorphSeq(GenerateSeq("1", "x"), strconv.Atoi))
+
+	var count int
+	for v, err := range FromEnvelopes(envelopes) {
+		if count == 0 {
+			c.Assert(v, Equals, 1)
+			c.Assert(err, IsNil)
+		} else {
+			c.Assert(err, NotNil)
+		}
+		count++
+	}
+	c.Assert(count, Equals, 2)
+}
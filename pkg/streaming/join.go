@@ -0,0 +1,263 @@
+package streaming
+
+import (
+	"context"
+	"time"
+)
+
+// JoinType of a stream join
+type JoinType int
+
+const (
+	// InnerJoin emit only items that found a match
+	InnerJoin JoinType = iota
+
+	// LeftOuterJoin also emit left items that never found a match, once they
+	// are evicted from the join state
+	LeftOuterJoin
+)
+
+// JoinOptions bounds the state a join holds. Window is how long an item
+// waits for a match, 0 keeps items until the inputs close. MaxState is the
+// maximum number of items held per side, the oldest is evicted first, 0 is
+// unbounded. RightLatest keeps only the latest right item per key, making
+// the right stream behave like a lookup table of its current values.
+type JoinOptions struct {
+	Type        JoinType
+	Window      time.Duration
+	MaxState    int
+	RightLatest bool
+}
+
+// Joined pair of items with the same key, Matched is false for left items
+// emitted by a LeftOuterJoin without a right match
+type Joined[L any, R any] struct {
+	Left    L
+	Right   R
+	Matched bool
+}
+
+type joinEntry[T any, K comparable] struct {
+	key     K
+	value   T
+	at      time.Time
+	matched bool
+	evicted bool
+}
+
+// joinState items of one side of the join, indexed by key and by arrival
+type joinState[T any, K comparable] struct {
+	byKey map[K][]*joinEntry[T, K]
+	queue []*joinEntry[T, K]
+	size  int
+}
+
+func newJoinState[T any, K comparable]() *joinState[T, K] {
+	return &joinState[T, K]{
+		byKey: make(map[K][]*joinEntry[T, K]),
+	}
+}
+
+func (t *joinState[T, K]) add(e *joinEntry[T, K]) {
+	t.byKey[e.key] = append(t.byKey[e.key], e)
+	t.queue = append(t.queue, e)
+	t.size++
+
+	// Entries removed out of order stay queued until they reach the front,
+	// compact once they dominate the queue
+	if len(t.queue) > 2*t.size+32 {
+		live := make([]*joinEntry[T, K], 0, t.size)
+		for _, ee := range t.queue {
+			if !ee.evicted {
+				live = append(live, ee)
+			}
+		}
+		t.queue = live
+	}
+}
+
+// replace removes every entry for key, used to keep only the latest value
+func (t *joinState[T, K]) replace(key K) {
+	for _, e := range t.byKey[key] {
+		e.evicted = true
+		t.size--
+	}
+	delete(t.byKey, key)
+}
+
+func (t *joinState[T, K]) remove(e *joinEntry[T, K]) {
+	e.evicted = true
+	t.size--
+
+	entries := t.byKey[e.key]
+	for i, ee := range entries {
+		if ee == e {
+			entries = append(entries[:i], entries[i+1:]...)
+			break
+		}
+	}
+
+	if len(entries) == 0 {
+		delete(t.byKey, e.key)
+	} else {
+		t.byKey[e.key] = entries
+	}
+}
+
+// evict removes entries that are older than cutoff or above maxState,
+// calling fn for each entry removed in arrival order
+func (t *joinState[T, K]) evict(cutoff time.Time, maxState int, fn func(*joinEntry[T, K]) bool) bool {
+	var n int
+	for _, e := range t.queue {
+		if e.evicted {
+			n++
+			continue
+		}
+
+		expired := !cutoff.IsZero() && e.at.Before(cutoff)
+		full := maxState > 0 && t.size > maxState
+		if !expired && !full {
+			break
+		}
+
+		t.remove(e)
+		n++
+
+		if !fn(e) {
+			t.queue = t.queue[n:]
+			return false
+		}
+	}
+	t.queue = t.queue[n:]
+	return true
+}
+
+// Join combine two streams by key. Every left item is paired with every
+// right item with the same key that is held in the join state, whichever
+// arrived first. Time is processing time, the window is measured from when
+// an item arrived. Unmatched left items of a LeftOuterJoin are emitted when
+// they are evicted and when both inputs close.
+func Join[L any, R any, K comparable](ctx context.Context, left <-chan L, right <-chan R, leftKey func(L) K, rightKey func(R) K, opts JoinOptions) <-chan Joined[L, R] {
+	out := make(chan Joined[L, R])
+
+	go func() {
+		defer close(out)
+
+		lefts := newJoinState[L, K]()
+		rights := newJoinState[R, K]()
+
+		send := func(j Joined[L, R]) bool {
+			select {
+			case out <- j:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		unmatched := func(e *joinEntry[L, K]) bool {
+			if opts.Type != LeftOuterJoin || e.matched {
+				return true
+			}
+			return send(Joined[L, R]{Left: e.value})
+		}
+
+		ignore := func(*joinEntry[R, K]) bool {
+			return true
+		}
+
+		evict := func(now time.Time) bool {
+			var cutoff time.Time
+			if opts.Window > 0 {
+				cutoff = now.Add(-opts.Window)
+			}
+
+			if !lefts.evict(cutoff, opts.MaxState, unmatched) {
+				return false
+			}
+			return rights.evict(cutoff, opts.MaxState, ignore)
+		}
+
+		var tick <-chan time.Time
+		if opts.Window > 0 {
+			// Sweep twice a window, tiny windows would round to no interval
+			ticker := time.NewTicker(max(opts.Window/2, time.Millisecond))
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for left != nil || right != nil {
+			select {
+			case <-ctx.Done():
+				return
+
+			case l, ok := <-left:
+				if !ok {
+					left = nil
+					continue
+				}
+
+				now := time.Now()
+				if !evict(now) {
+					return
+				}
+
+				e := &joinEntry[L, K]{key: leftKey(l), value: l, at: now}
+				for _, r := range rights.byKey[e.key] {
+					e.matched = true
+					if !send(Joined[L, R]{Left: l, Right: r.value, Matched: true}) {
+						return
+					}
+				}
+				lefts.add(e)
+				if !evict(now) {
+					return
+				}
+
+			case r, ok := <-right:
+				if !ok {
+					right = nil
+					continue
+				}
+
+				now := time.Now()
+				if !evict(now) {
+					return
+				}
+
+				e := &joinEntry[R, K]{key: rightKey(r), value: r, at: now}
+				if opts.RightLatest {
+					rights.replace(e.key)
+				}
+
+				for _, l := range lefts.byKey[e.key] {
+					l.matched = true
+					if !send(Joined[L, R]{Left: l.value, Right: r, Matched: true}) {
+						return
+					}
+				}
+				rights.add(e)
+				if !evict(now) {
+					return
+				}
+
+			case now := <-tick:
+				if !evict(now) {
+					return
+				}
+			}
+		}
+
+		// Both inputs closed, flush unmatched left items in arrival order
+		for _, e := range lefts.queue {
+			if e.evicted {
+				continue
+			}
+			if !unmatched(e) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,193 @@
+package streaming
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type JoinSuite struct{}
+
+var _ = Suite(&JoinSuite{})
+
+type click struct {
+	user string
+	page string
+}
+
+type profile struct {
+	user    string
+	country string
+}
+
+func clickUser(c click) string {
+	return c.user
+}
+
+func profileUser(p profile) string {
+	return p.user
+}
+
+func (s *JoinSuite) Test_InnerJoin(c *C) {
+	ctx := context.Background()
+
+	left := make(chan click)
+	right := make(chan profile)
+
+	out := Join(ctx, left, right, clickUser, profileUser, JoinOptions{})
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		left <- click{"a", "home"}
+		right <- profile{"a", "ca"}
+		right <- profile{"b", "us"}
+		left <- click{"b", "about"}
+		left <- click{"c", "home"}
+	}()
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 2)
+	c.Assert(results[0], Equals, Joined[click, profile]{click{"a", "home"}, profile{"a", "ca"}, true})
+	c.Assert(results[1], Equals, Joined[click, profile]{click{"b", "about"}, profile{"b", "us"}, true})
+}
+
+func (s *JoinSuite) Test_LeftOuterJoin(c *C) {
+	ctx := context.Background()
+
+	left := make(chan click)
+	right := make(chan profile)
+
+	out := Join(ctx, left, right, clickUser, profileUser, JoinOptions{Type: LeftOuterJoin})
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		left <- click{"a", "home"}
+		left <- click{"c", "home"}
+		right <- profile{"a", "ca"}
+	}()
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 2)
+	c.Assert(results[0].Matched, Equals, true)
+	c.Assert(results[0].Right.country, Equals, "ca")
+	c.Assert(results[1].Matched, Equals, false)
+	c.Assert(results[1].Left.user, Equals, "c")
+}
+
+func (s *JoinSuite) Test_Join_TinyWindow(c *C) {
+	ctx := context.Background()
+
+	left := make(chan click)
+	right := make(chan profile)
+
+	out := Join(ctx, left, right, clickUser, profileUser, JoinOptions{
+		Type:   LeftOuterJoin,
+		Window: time.Nanosecond,
+	})
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		left <- click{"a", "home"}
+	}()
+
+	c.Assert(Gather(out), DeepEquals, []Joined[click, profile]{{Left: click{"a", "home"}}})
+}
+
+func (s *JoinSuite) Test_Join_Window(c *C) {
+	ctx := context.Background()
+
+	left := make(chan click)
+	right := make(chan profile)
+
+	out := Join(ctx, left, right, clickUser, profileUser, JoinOptions{
+		Type:   LeftOuterJoin,
+		Window: 20 * time.Millisecond,
+	})
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		left <- click{"a", "home"}
+		time.Sleep(60 * time.Millisecond)
+		right <- profile{"a", "ca"}
+	}()
+
+	// The click expires before the profile arrives
+	results := Gather(out)
+	c.Assert(results, HasLen, 1)
+	c.Assert(results[0].Matched, Equals, false)
+}
+
+func (s *JoinSuite) Test_Join_MaxState(c *C) {
+	ctx := context.Background()
+
+	left := make(chan click)
+	right := make(chan profile)
+
+	out := Join(ctx, left, right, clickUser, profileUser, JoinOptions{
+		Type:     LeftOuterJoin,
+		MaxState: 2,
+	})
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		left <- click{"a", "1"}
+		left <- click{"a", "2"}
+		left <- click{"a", "3"}
+		right <- profile{"a", "ca"}
+	}()
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 3)
+
+	// The oldest click is evicted unmatched to stay within MaxState
+	c.Assert(results[0].Left.page, Equals, "1")
+	c.Assert(results[0].Matched, Equals, false)
+	c.Assert(results[1].Left.page, Equals, "2")
+	c.Assert(results[1].Matched, Equals, true)
+	c.Assert(results[2].Left.page, Equals, "3")
+	c.Assert(results[2].Matched, Equals, true)
+}
+
+func (s *JoinSuite) Test_Join_RightLatest(c *C) {
+	ctx := context.Background()
+
+	left := make(chan click)
+	right := make(chan profile)
+
+	out := Join(ctx, left, right, clickUser, profileUser, JoinOptions{RightLatest: true})
+
+	go func() {
+		defer close(left)
+		defer close(right)
+
+		right <- profile{"a", "ca"}
+		right <- profile{"a", "us"}
+		left <- click{"a", "home"}
+	}()
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 1)
+	c.Assert(results[0].Right.country, Equals, "us")
+}
+
+func (s *JoinSuite) Test_Join_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	out := Join(ctx, make(chan click), make(chan profile), clickUser, profileUser, JoinOptions{})
+	cancel()
+
+	for _ = range out {
+		c.Fail()
+	}
+}
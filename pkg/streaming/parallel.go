@@ -0,0 +1,198 @@
+package streaming
+
+import (
+	"context"
+	"sync"
+)
+
+// ParallelMap convert every item with fn using a pool of workers goroutines.
+// Results are emitted in completion order, use ParallelMapOrdered if input
+// order must be kept. Errors are sent on the error channel as *ItemError and
+// the failing item is dropped. The error channel must be consumed.
+func ParallelMap[I any, O any](ctx context.Context, in <-chan I, workers int, fn func(context.Context, I) (O, error)) (<-chan O, <-chan error) {
+	if workers < 1 {
+		workers = 1
+	}
+
+	out := make(chan O)
+	errCh := make(chan error)
+
+	var wg sync.WaitGroup
+
+	worker := func() {
+		defer wg.Done()
+
+		for {
+			var v I
+			var ok bool
+
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			o, err := fn(ctx, v)
+			if err != nil {
+				select {
+				case errCh <- &ItemError[I]{Item: v, Err: err}:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			select {
+			case out <- o:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+
+	go func() {
+		defer close(out)
+		defer close(errCh)
+		wg.Wait()
+	}()
+
+	return out, errCh
+}
+
+type sequenced[T any] struct {
+	seq   uint64
+	value T
+	err   error
+}
+
+// ParallelMapOrdered convert every item with fn using a pool of workers
+// goroutines, emitting results in input order. At most window items are in
+// flight or waiting in the reorder buffer at any time, a slow item stalls
+// the pool once the window is full. A window less than workers defaults to
+// workers. Errors are sent on the error channel as *ItemError, in input
+// order, and the failing item is dropped. The error channel must be consumed.
+func ParallelMapOrdered[I any, O any](ctx context.Context, in <-chan I, workers, window int, fn func(context.Context, I) (O, error)) (<-chan O, <-chan error) {
+	if workers < 1 {
+		workers = 1
+	}
+	if window < workers {
+		window = workers
+	}
+
+	out := make(chan O)
+	errCh := make(chan error)
+
+	jobCh := make(chan sequenced[I])
+	resultCh := make(chan sequenced[O])
+	slots := make(chan struct{}, window)
+
+	// Dispatch items in sequence, a slot is held until the item is emitted
+	go func() {
+		defer close(jobCh)
+
+		var seq uint64
+		for {
+			var v I
+			var ok bool
+
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobCh <- sequenced[I]{seq: seq, value: v}:
+			case <-ctx.Done():
+				return
+			}
+			seq++
+		}
+	}()
+
+	var wg sync.WaitGroup
+
+	worker := func() {
+		defer wg.Done()
+
+		for job := range jobCh {
+			o, err := fn(ctx, job.value)
+			if err != nil {
+				err = &ItemError[I]{Item: job.value, Err: err}
+			}
+
+			select {
+			case resultCh <- sequenced[O]{seq: job.seq, value: o, err: err}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+
+	go func() {
+		wg.Wait()
+		close(resultCh)
+	}()
+
+	// Reorder results, releasing a slot for every item emitted
+	go func() {
+		defer close(out)
+		defer close(errCh)
+
+		pending := make(map[uint64]sequenced[O], window)
+
+		var next uint64
+		for r := range resultCh {
+			pending[r.seq] = r
+
+			for {
+				p, found := pending[next]
+				if !found {
+					break
+				}
+				delete(pending, next)
+				next++
+
+				if p.err != nil {
+					select {
+					case errCh <- p.err:
+					case <-ctx.Done():
+						return
+					}
+				} else {
+					select {
+					case out <- p.value:
+					case <-ctx.Done():
+						return
+					}
+				}
+
+				<-slots
+			}
+		}
+	}()
+
+	return out, errCh
+}
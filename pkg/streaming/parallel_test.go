@@ -0,0 +1,140 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type ParallelSuite struct{}
+
+var _ = Suite(&ParallelSuite{})
+
+func sequence(n int) []int {
+	arr := make([]int, n)
+	for i := range arr {
+		arr[i] = i
+	}
+	return arr
+}
+
+func jitterSquare(_ context.Context, i int) (int, error) {
+	time.Sleep(time.Duration(rand.Intn(500)) * time.Microsecond)
+	return i * i, nil
+}
+
+func (s *ParallelSuite) Test_ParallelMap(c *C) {
+	ctx := context.Background()
+
+	out, errCh := ParallelMap(ctx, GenerateContext(ctx, sequence(100)...), 8, jitterSquare)
+	go Consume(errCh)
+
+	results := Gather(out)
+	sort.Ints(results)
+
+	c.Assert(results, HasLen, 100)
+	for i, v := range results {
+		c.Assert(v, Equals, i*i)
+	}
+}
+
+func (s *ParallelSuite) Test_ParallelMap_Concurrency(c *C) {
+	ctx := context.Background()
+
+	var running, peak int32
+	fn := func(_ context.Context, i int) (int, error) {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+				break
+			}
+		}
+		time.Sleep(2 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return i, nil
+	}
+
+	out, errCh := ParallelMap(ctx, GenerateContext(ctx, sequence(40)...), 4, fn)
+	go Consume(errCh)
+	Consume(out)
+
+	c.Assert(atomic.LoadInt32(&peak) <= 4, Equals, true)
+	c.Assert(atomic.LoadInt32(&peak) > 1, Equals, true)
+}
+
+func (s *ParallelSuite) Test_ParallelMapOrdered(c *C) {
+	ctx := context.Background()
+
+	out, errCh := ParallelMapOrdered(ctx, GenerateContext(ctx, sequence(200)...), 8, 16, jitterSquare)
+	go Consume(errCh)
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 200)
+	for i, v := range results {
+		c.Assert(v, Equals, i*i)
+	}
+}
+
+func (s *ParallelSuite) Test_ParallelMapOrdered_Errors(c *C) {
+	ctx := context.Background()
+	odd := errors.New("odd")
+
+	fn := func(_ context.Context, i int) (int, error) {
+		if i%2 == 1 {
+			return 0, odd
+		}
+		return i, nil
+	}
+
+	out, errCh := ParallelMapOrdered(ctx, GenerateContext(ctx, sequence(10)...), 3, 4, fn)
+
+	errs := make(chan []error)
+	go func() {
+		var arr []error
+		for err := range errCh {
+			arr = append(arr, err)
+		}
+		errs <- arr
+	}()
+
+	c.Assert(Gather(out), DeepEquals, []int{0, 2, 4, 6, 8})
+
+	arr := <-errs
+	c.Assert(arr, HasLen, 5)
+	for i, err := range arr {
+		var itemErr *ItemError[int]
+		c.Assert(errors.As(err, &itemErr), Equals, true)
+		c.Assert(itemErr.Item, Equals, i*2+1)
+		c.Assert(errors.Is(err, odd), Equals, true)
+	}
+}
+
+func (s *ParallelSuite) Test_ParallelMapOrdered_Cancel(c *C) {
+	g, ctx := NewGroup(context.Background())
+
+	fn := func(_ context.Context, i int) (int, error) {
+		if i == 5 {
+			return 0, errors.New("fail")
+		}
+		return i, nil
+	}
+
+	out, errCh := ParallelMapOrdered(ctx, GenerateContext(ctx, sequence(1000)...), 4, 8, fn)
+	g.Watch(errCh)
+
+	results := Gather(out)
+	c.Assert(g.Wait(), NotNil)
+	c.Assert(len(results) < 1000, Equals, true)
+	for i, v := range results {
+		if i >= 5 {
+			i++
+		}
+		c.Assert(v, Equals, i)
+	}
+}
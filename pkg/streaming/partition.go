@@ -0,0 +1,128 @@
+package streaming
+
+import (
+	"context"
+	"time"
+
+	"github.com/sjhitchner/toolbox/pkg/set"
+)
+
+// Keyed values that share the same key
+type Keyed[K comparable, T any] struct {
+	Key    K
+	Values []T
+}
+
+// HashKey returns a stable hash of k, used to route keys to partitions
+func HashKey[K comparable](k K) uint64 {
+	return set.Hash(k)
+}
+
+// PartitionBy route every item to one of n channels chosen by the hash of its
+// key. All items with the same key go to the same channel so per-key order
+// is kept. A slow consumer blocks every partition, all channels must be
+// consumed until they close. The channels are closed when in is closed or
+// ctx is cancelled.
+func PartitionBy[T any, K comparable](ctx context.Context, in <-chan T, n int, keyFn func(T) K) []<-chan T {
+	if n < 1 {
+		n = 1
+	}
+
+	streams := make([]chan T, n)
+	outs := make([]<-chan T, n)
+	for i := range streams {
+		streams[i] = make(chan T)
+		outs[i] = streams[i]
+	}
+
+	go func() {
+		defer func() {
+			for i := range streams {
+				close(streams[i])
+			}
+		}()
+
+		for {
+			select {
+			case val, ok := <-in:
+				if !ok {
+					return
+				}
+
+				select {
+				case streams[HashKey(keyFn(val))%uint64(n)] <- val:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return outs
+}
+
+// GroupBy collect items by key, emitting a Keyed group for every key seen
+// once per window. Groups are emitted in the order their key was first seen
+// within the window. Remaining groups are emitted when in is closed and
+// discarded when ctx is cancelled. A window of 0 or less emits the groups
+// only once in is closed.
+func GroupBy[T any, K comparable](ctx context.Context, in <-chan T, keyFn func(T) K, window time.Duration) <-chan Keyed[K, T] {
+	out := make(chan Keyed[K, T])
+
+	go func() {
+		defer close(out)
+
+		groups := make(map[K][]T)
+		keys := make([]K, 0)
+
+		// flush returns false if ctx was cancelled
+		flush := func() bool {
+			for _, k := range keys {
+				select {
+				case out <- Keyed[K, T]{Key: k, Values: groups[k]}:
+				case <-ctx.Done():
+					return false
+				}
+			}
+			groups = make(map[K][]T)
+			keys = keys[:0]
+			return true
+		}
+
+		// A nil tick channel never fires
+		var tick <-chan time.Time
+		if window > 0 {
+			ticker := time.NewTicker(window)
+			defer ticker.Stop()
+			tick = ticker.C
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case item, ok := <-in:
+				if !ok {
+					flush()
+					return
+				}
+
+				k := keyFn(item)
+				if _, found := groups[k]; !found {
+					keys = append(keys, k)
+				}
+				groups[k] = append(groups[k], item)
+
+			case <-tick:
+				if !flush() {
+					return
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,143 @@
+package streaming
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type PartitionSuite struct{}
+
+var _ = Suite(&PartitionSuite{})
+
+type event struct {
+	entity string
+	seq    int
+}
+
+func (s *PartitionSuite) Test_PartitionBy(c *C) {
+	var events []event
+	for i := 0; i < 50; i++ {
+		for _, entity := range []string{"a", "b", "c", "d", "e"} {
+			events = append(events, event{entity: entity, seq: i})
+		}
+	}
+
+	streams := PartitionBy(context.Background(), Generate(nil, events...), 3, func(e event) string {
+		return e.entity
+	})
+	c.Assert(streams, HasLen, 3)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	seen := make(map[string]int)
+	owner := make(map[string]int)
+
+	wg.Add(len(streams))
+	for i, ch := range streams {
+		go func(i int, ch <-chan event) {
+			defer wg.Done()
+
+			last := make(map[string]int)
+			for e := range ch {
+				if prev, found := last[e.entity]; found {
+					c.Check(e.seq, Equals, prev+1)
+				}
+				last[e.entity] = e.seq
+
+				mu.Lock()
+				if o, found := owner[e.entity]; found {
+					c.Check(o, Equals, i)
+				}
+				owner[e.entity] = i
+				seen[e.entity]++
+				mu.Unlock()
+			}
+		}(i, ch)
+	}
+	wg.Wait()
+
+	c.Assert(seen, HasLen, 5)
+	for _, n := range seen {
+		c.Assert(n, Equals, 50)
+	}
+}
+
+func (s *PartitionSuite) Test_HashKey(c *C) {
+	c.Assert(HashKey("abc"), Equals, HashKey("abc"))
+	c.Assert(HashKey(10), Equals, HashKey(10))
+	c.Assert(HashKey(10) != HashKey(11), Equals, true)
+	c.Assert(HashKey(event{"a", 1}), Equals, HashKey(event{"a", 1}))
+
+	// Equal keys share a partition even when their bits differ
+	c.Assert(HashKey(math.Copysign(0, -1)), Equals, HashKey(0.0))
+}
+
+func (s *PartitionSuite) Test_GroupBy(c *C) {
+	in := Generate(nil, 1, 2, 3, 4, 5, 6, 7)
+
+	out := GroupBy(context.Background(), in, func(i int) string {
+		if i%2 == 0 {
+			return "even"
+		}
+		return "odd"
+	}, time.Hour)
+
+	groups := Gather(out)
+	c.Assert(groups, HasLen, 2)
+	c.Assert(groups[0].Key, Equals, "odd")
+	c.Assert(groups[0].Values, DeepEquals, []int{1, 3, 5, 7})
+	c.Assert(groups[1].Key, Equals, "even")
+	c.Assert(groups[1].Values, DeepEquals, []int{2, 4, 6})
+}
+
+func (s *PartitionSuite) Test_GroupBy_Window(c *C) {
+	in := make(chan int)
+	out := GroupBy(context.Background(), in, func(i int) int { return i }, 20*time.Millisecond)
+
+	in <- 1
+	in <- 1
+
+	group := <-out
+	c.Assert(group.Key, Equals, 1)
+	c.Assert(group.Values, DeepEquals, []int{1, 1})
+
+	in <- 1
+	close(in)
+
+	group = <-out
+	c.Assert(group.Values, DeepEquals, []int{1})
+
+	_, ok := <-out
+	c.Assert(ok, Equals, false)
+}
+
+func (s *PartitionSuite) Test_GroupBy_NoWindow(c *C) {
+	out := GroupBy(context.Background(), Generate(nil, 1, 2, 1), func(i int) int { return i }, 0)
+
+	groups := Gather(out)
+	c.Assert(groups, HasLen, 2)
+	c.Assert(groups[0].Values, DeepEquals, []int{1, 1})
+}
+
+func (s *PartitionSuite) Test_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	in := make(chan int, 2)
+	streams := PartitionBy(ctx, in, 2, func(i int) int { return i })
+	groups := GroupBy(ctx, in, func(i int) int { return i }, time.Hour)
+
+	// Nobody reads the outputs, cancelling must still close them
+	in <- 1
+	in <- 2
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	for _, ch := range streams {
+		Consume(ch)
+	}
+	Consume(groups)
+}
@@ -0,0 +1,556 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	gv "github.com/sjhitchner/toolbox/pkg/graphviz"
+	"github.com/sjhitchner/toolbox/pkg/metrics"
+)
+
+const (
+	DefaultQueueSize = 16
+)
+
+// Pipeline a set of named stages sharing a context. Every stage records
+// throughput, latency and queue depth so a stalled pipeline can be inspected
+// with Stats, published with Report or drawn with Graph. The first stage to
+// fail cancels the pipeline.
+//
+//	p := streaming.NewPipeline(ctx, "ingest")
+//	rows := streaming.From(p, "files", files)
+//	parsed := streaming.Map(rows, "parse", 4, parse)
+//	batches := streaming.Via(parsed, "batch", func(ctx context.Context, in <-chan Row) <-chan []Row {
+//		return streaming.BatchContext(ctx, in, 100, time.Second)
+//	})
+//	streaming.Sink(batches, "load", load)
+//	err := p.Wait()
+type Pipeline struct {
+	Name      string
+	QueueSize int
+
+	ctx     context.Context
+	group   *Group
+	drainer *Drainer
+
+	mu     sync.Mutex
+	stages []*Stage
+}
+
+// NewPipeline create an empty pipeline, QueueSize must be set before stages
+// are added
+func NewPipeline(ctx context.Context, name string) *Pipeline {
+	drainer, ctx := NewDrainer(ctx)
+	return &Pipeline{
+		Name:      name,
+		QueueSize: DefaultQueueSize,
+		ctx:       ctx,
+		group:     drainer.group,
+		drainer:   drainer,
+	}
+}
+
+// Context the pipeline runs under, cancelled when a stage fails
+func (t *Pipeline) Context() context.Context {
+	return t.ctx
+}
+
+// Fail cancel the pipeline with err
+func (t *Pipeline) Fail(err error) {
+	t.group.Fail(err)
+}
+
+// Wait for every sink to finish, returns the first stage error
+func (t *Pipeline) Wait() error {
+	return t.group.Wait()
+}
+
+// Drain stop every source and wait up to timeout for the items in flight to
+// reach the sinks, see Drainer.Drain
+func (t *Pipeline) Drain(timeout time.Duration) error {
+	return t.drainer.Drain(timeout)
+}
+
+// DrainOn drain the pipeline when signal closes, eg utils.Shutdown()
+func (t *Pipeline) DrainOn(signal <-chan struct{}, timeout time.Duration) <-chan error {
+	return t.drainer.DrainOn(signal, timeout)
+}
+
+// Stage returns the stage with name, nil if not found
+func (t *Pipeline) Stage(name string) *Stage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, s := range t.stages {
+		if s.Name == name {
+			return s
+		}
+	}
+	return nil
+}
+
+// Stats snapshot of every stage in the order they were added
+func (t *Pipeline) Stats() []StageStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := make([]StageStats, len(t.stages))
+	for i, s := range t.stages {
+		stats[i] = s.Stats()
+	}
+	return stats
+}
+
+func (t *Pipeline) addStage(name, kind string, depth func() int, inputs ...*Stage) *Stage {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stage := &Stage{
+		ID:     fmt.Sprintf("stage%d", len(t.stages)),
+		Name:   name,
+		Kind:   kind,
+		inputs: inputs,
+		depth:  depth,
+	}
+	t.stages = append(t.stages, stage)
+	return stage
+}
+
+// Report publish stage metrics through pkg/metrics every interval until the
+// pipeline finishes. Counts are published as deltas since the last report.
+// Tags: pipeline, stage
+func (t *Pipeline) Report(interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last := make(map[*Stage]StageStats)
+		report := func() {
+			t.mu.Lock()
+			stages := append([]*Stage(nil), t.stages...)
+			t.mu.Unlock()
+
+			for _, s := range stages {
+				stats := s.Stats()
+				prev := last[s]
+				last[s] = stats
+
+				tags := []string{"pipeline", t.Name, "stage", s.Name}
+				metrics.CounterAt64("pipeline_stage_in", stats.In-prev.In, tags...).Emit()
+				metrics.CounterAt64("pipeline_stage_out", stats.Out-prev.Out, tags...).Emit()
+				metrics.CounterAt64("pipeline_stage_error", stats.Errors-prev.Errors, tags...).Emit()
+				metrics.GaugeAt("pipeline_stage_queue_depth", float64(stats.Depth), tags...).Emit()
+				metrics.GaugeAt("pipeline_stage_busy", float64(stats.Busy), tags...).Emit()
+				metrics.GaugeAt("pipeline_stage_blocked", float64(stats.Blocked), tags...).Emit()
+				if stats.Latency > 0 {
+					metrics.HistogramAt("pipeline_stage_latency", stats.Latency.Seconds(), tags...).Emit()
+				}
+			}
+		}
+
+		for {
+			select {
+			case <-ticker.C:
+				report()
+			case <-t.ctx.Done():
+				report()
+				return
+			}
+		}
+	}()
+}
+
+// Graph topology of the pipeline, each node is labelled with its stage
+// stats at the time of the call
+func (t *Pipeline) Graph() *gv.Graph {
+	t.mu.Lock()
+	stages := append([]*Stage(nil), t.stages...)
+	t.mu.Unlock()
+
+	graph := &gv.Graph{
+		ID:        t.Name,
+		IsDigraph: true,
+		Attributes: map[string]interface{}{
+			"rankdir": "LR",
+		},
+		Global: map[string]map[string]interface{}{
+			"node": {"shape": "box"},
+		},
+	}
+
+	for _, s := range stages {
+		graph.AddNode(&gv.Node{
+			ID:    s.ID,
+			Label: s.Stats().Label(),
+		})
+	}
+
+	for _, s := range stages {
+		for _, input := range s.inputs {
+			graph.Connect(input.ID, s.ID)
+		}
+	}
+
+	return graph
+}
+
+// Stage a named step in a Pipeline
+type Stage struct {
+	ID     string
+	Name   string
+	Kind   string
+	inputs []*Stage
+
+	in      atomic.Int64
+	out     atomic.Int64
+	errors  atomic.Int64
+	elapsed atomic.Int64
+	busy    atomic.Int32
+	blocked atomic.Int32
+	done    atomic.Bool
+	depth   func() int
+}
+
+// StageStats snapshot of a stage. Latency is the mean time spent processing
+// an item, 0 for stages that are not measured. Depth is the number of items
+// waiting in the stage output queue. Busy is the number of workers processing
+// an item and Blocked the number waiting for downstream to accept one.
+type StageStats struct {
+	Name    string
+	Kind    string
+	In      int64
+	Out     int64
+	Errors  int64
+	Latency time.Duration
+	Depth   int
+	Busy    int
+	Blocked int
+	Done    bool
+}
+
+// Stats snapshot of the stage
+func (t *Stage) Stats() StageStats {
+	stats := StageStats{
+		Name:    t.Name,
+		Kind:    t.Kind,
+		In:      t.in.Load(),
+		Out:     t.out.Load(),
+		Errors:  t.errors.Load(),
+		Busy:    int(t.busy.Load()),
+		Blocked: int(t.blocked.Load()),
+		Done:    t.done.Load(),
+	}
+
+	if n := stats.Out + stats.Errors; n > 0 && t.elapsed.Load() > 0 {
+		stats.Latency = time.Duration(t.elapsed.Load() / n)
+	}
+
+	if t.depth != nil {
+		stats.Depth = t.depth()
+	}
+
+	return stats
+}
+
+// Label multi-line summary used for graph nodes
+func (t StageStats) Label() string {
+	lines := []string{
+		fmt.Sprintf("%s (%s)", t.Name, t.Kind),
+		fmt.Sprintf("in=%d out=%d err=%d", t.In, t.Out, t.Errors),
+		fmt.Sprintf("depth=%d busy=%d blocked=%d", t.Depth, t.Busy, t.Blocked),
+	}
+	if t.Latency > 0 {
+		lines = append(lines, fmt.Sprintf("latency=%s", t.Latency))
+	}
+	if t.Done {
+		lines = append(lines, "done")
+	}
+	return strings.Join(lines, `\n`)
+}
+
+func (t *Stage) String() string {
+	return strings.ReplaceAll(t.Stats().Label(), `\n`, " ")
+}
+
+// Flow output of a pipeline stage, pass it to the next stage
+type Flow[T any] struct {
+	C        <-chan T
+	pipeline *Pipeline
+	stage    *Stage
+}
+
+func (t Flow[T]) Pipeline() *Pipeline {
+	return t.pipeline
+}
+
+func (t Flow[T]) Stage() *Stage {
+	return t.stage
+}
+
+// stageSend send to out tracking time spent blocked on downstream
+func stageSend[T any](ctx context.Context, stage *Stage, out chan<- T, v T) bool {
+	stage.blocked.Add(1)
+	defer stage.blocked.Add(-1)
+
+	select {
+	case out <- v:
+		stage.out.Add(1)
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+func queueDepth[T any](ch chan T) func() int {
+	return func() int {
+		return len(ch)
+	}
+}
+
+// From add a source stage reading from ch, the source stops reading and
+// closes its output when the pipeline is drained
+func From[T any](p *Pipeline, name string, ch <-chan T) Flow[T] {
+	out := make(chan T, p.QueueSize)
+	stage := p.addStage(name, "source", queueDepth(out))
+
+	go func() {
+		defer close(out)
+		defer stage.done.Store(true)
+
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+				stage.in.Add(1)
+
+				if !stageSend(p.ctx, stage, out, v) {
+					return
+				}
+			case <-p.drainer.Stopping():
+				return
+			case <-p.ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return Flow[T]{C: out, pipeline: p, stage: stage}
+}
+
+// Map add a stage converting every item with fn on workers goroutines,
+// results are emitted in completion order. An error fails the pipeline.
+func Map[I any, O any](in Flow[I], name string, workers int, fn func(context.Context, I) (O, error)) Flow[O] {
+	p := in.pipeline
+	ctx := p.ctx
+
+	if workers < 1 {
+		workers = 1
+	}
+
+	out := make(chan O, p.QueueSize)
+	stage := p.addStage(name, "map", queueDepth(out), in.stage)
+
+	var wg sync.WaitGroup
+
+	worker := func() {
+		defer wg.Done()
+
+		for {
+			var v I
+			var ok bool
+
+			select {
+			case v, ok = <-in.C:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+			stage.in.Add(1)
+
+			stage.busy.Add(1)
+			start := time.Now()
+			o, err := fn(ctx, v)
+			stage.elapsed.Add(int64(time.Since(start)))
+			stage.busy.Add(-1)
+
+			if err != nil {
+				stage.errors.Add(1)
+				p.Fail(fmt.Errorf("stage %s: %w", name, &ItemError[I]{Item: v, Err: err}))
+				return
+			}
+
+			if !stageSend(ctx, stage, out, o) {
+				return
+			}
+		}
+	}
+
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go worker()
+	}
+
+	go func() {
+		defer close(out)
+		defer stage.done.Store(true)
+		wg.Wait()
+	}()
+
+	return Flow[O]{C: out, pipeline: p, stage: stage}
+}
+
+// Via add a stage built from any channel stage (eg BatchContext, Throttle,
+// Buffer), only throughput and queue depth are measured
+func Via[I any, O any](in Flow[I], name string, fn func(context.Context, <-chan I) <-chan O) Flow[O] {
+	p := in.pipeline
+	ctx := p.ctx
+
+	inner := make(chan I)
+	out := make(chan O, p.QueueSize)
+	stage := p.addStage(name, "via", queueDepth(out), in.stage)
+
+	go func() {
+		defer close(inner)
+
+		for {
+			select {
+			case v, ok := <-in.C:
+				if !ok {
+					return
+				}
+				stage.in.Add(1)
+
+				select {
+				case inner <- v:
+				case <-ctx.Done():
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	result := fn(ctx, inner)
+
+	go func() {
+		defer close(out)
+		defer stage.done.Store(true)
+
+		for {
+			select {
+			case v, ok := <-result:
+				if !ok {
+					return
+				}
+
+				if !stageSend(ctx, stage, out, v) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return Flow[O]{C: out, pipeline: p, stage: stage}
+}
+
+// MergeFlows add a stage interleaving several flows of the same pipeline
+func MergeFlows[T any](name string, flows ...Flow[T]) Flow[T] {
+	if len(flows) == 0 {
+		panic("need at least one flow")
+	}
+
+	p := flows[0].pipeline
+	ctx := p.ctx
+
+	inputs := make([]*Stage, len(flows))
+	for i, f := range flows {
+		inputs[i] = f.stage
+	}
+
+	out := make(chan T, p.QueueSize)
+	stage := p.addStage(name, "merge", queueDepth(out), inputs...)
+
+	var wg sync.WaitGroup
+
+	merge := func(ch <-chan T) {
+		defer wg.Done()
+
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					return
+				}
+				stage.in.Add(1)
+
+				if !stageSend(ctx, stage, out, v) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+
+	wg.Add(len(flows))
+	for _, f := range flows {
+		go merge(f.C)
+	}
+
+	go func() {
+		defer close(out)
+		defer stage.done.Store(true)
+		wg.Wait()
+	}()
+
+	return Flow[T]{C: out, pipeline: p, stage: stage}
+}
+
+// Sink add a terminal stage calling fn for every item, Pipeline.Wait
+// returns once every sink has finished. An error fails the pipeline.
+func Sink[T any](in Flow[T], name string, fn func(context.Context, T) error) {
+	p := in.pipeline
+	stage := p.addStage(name, "sink", nil, in.stage)
+
+	p.group.Go(func(ctx context.Context) error {
+		defer stage.done.Store(true)
+
+		for {
+			var v T
+			var ok bool
+
+			select {
+			case v, ok = <-in.C:
+				if !ok {
+					return nil
+				}
+			case <-ctx.Done():
+				return context.Cause(ctx)
+			}
+			stage.in.Add(1)
+
+			stage.busy.Add(1)
+			start := time.Now()
+			err := fn(ctx, v)
+			stage.elapsed.Add(int64(time.Since(start)))
+			stage.busy.Add(-1)
+
+			if err != nil {
+				stage.errors.Add(1)
+				return fmt.Errorf("stage %s: %w", name, &ItemError[T]{Item: v, Err: err})
+			}
+			stage.out.Add(1)
+		}
+	})
+}
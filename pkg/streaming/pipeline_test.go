@@ -0,0 +1,142 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type PipelineSuite struct{}
+
+var _ = Suite(&PipelineSuite{})
+
+func (s *PipelineSuite) Test_Pipeline(c *C) {
+	p := NewPipeline(context.Background(), "test")
+
+	source := From(p, "source", Generate(nil, "1", "2", "3", "4", "5"))
+	parsed := Map(source, "parse", 2, func(_ context.Context, v string) (int, error) {
+		return strconv.Atoi(v)
+	})
+	batches := Via(parsed, "batch", func(ctx context.Context, in <-chan int) <-chan []int {
+		return BatchContext(ctx, in, 2, time.Second)
+	})
+
+	var mu sync.Mutex
+	var total int
+	Sink(batches, "sum", func(_ context.Context, batch []int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, v := range batch {
+			total += v
+		}
+		return nil
+	})
+
+	c.Assert(p.Wait(), IsNil)
+	c.Assert(total, Equals, 15)
+
+	stats := p.Stats()
+	c.Assert(stats, HasLen, 4)
+
+	c.Assert(stats[0].Name, Equals, "source")
+	c.Assert(stats[0].Out, Equals, int64(5))
+
+	c.Assert(stats[1].Name, Equals, "parse")
+	c.Assert(stats[1].In, Equals, int64(5))
+	c.Assert(stats[1].Out, Equals, int64(5))
+	c.Assert(stats[1].Done, Equals, true)
+
+	c.Assert(stats[2].In, Equals, int64(5))
+	c.Assert(stats[2].Out, Equals, int64(3))
+
+	c.Assert(stats[3].In, Equals, int64(3))
+	c.Assert(stats[3].Busy, Equals, 0)
+}
+
+func (s *PipelineSuite) Test_Pipeline_Fail(c *C) {
+	p := NewPipeline(context.Background(), "test")
+
+	source := From(p, "source", Generate(nil, "1", "x", "3"))
+	parsed := Map(source, "parse", 1, func(_ context.Context, v string) (int, error) {
+		return strconv.Atoi(v)
+	})
+	Sink(parsed, "sink", func(context.Context, int) error {
+		return nil
+	})
+
+	err := p.Wait()
+	c.Assert(err, ErrorMatches, "stage parse: .*")
+
+	var itemErr *ItemError[string]
+	c.Assert(errors.As(err, &itemErr), Equals, true)
+	c.Assert(itemErr.Item, Equals, "x")
+	c.Assert(p.Stage("parse").Stats().Errors, Equals, int64(1))
+}
+
+func (s *PipelineSuite) Test_Pipeline_Stalled(c *C) {
+	p := NewPipeline(context.Background(), "test")
+	p.QueueSize = 2
+
+	release := make(chan struct{})
+
+	source := From(p, "source", Generate(nil, sequence(10)...))
+	Sink(source, "slow", func(context.Context, int) error {
+		<-release
+		return nil
+	})
+
+	// The sink is stuck so the source fills its queue and blocks
+	deadline := time.Now().Add(time.Second)
+	for p.Stage("source").Stats().Blocked == 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+
+	stats := p.Stage("source").Stats()
+	c.Assert(stats.Blocked, Equals, 1)
+	c.Assert(stats.Depth, Equals, 2)
+	c.Assert(p.Stage("slow").Stats().Busy, Equals, 1)
+
+	close(release)
+	c.Assert(p.Wait(), IsNil)
+}
+
+func (s *PipelineSuite) Test_Pipeline_Graph(c *C) {
+	p := NewPipeline(context.Background(), "graph")
+
+	a := From(p, "a", Generate(nil, 1, 2))
+	b := From(p, "b", Generate(nil, 3, 4))
+	merged := MergeFlows("merge", a, b)
+	Sink(merged, "sink", func(context.Context, int) error {
+		return nil
+	})
+	c.Assert(p.Wait(), IsNil)
+
+	graph := p.Graph()
+	c.Assert(graph.Nodes, HasLen, 4)
+	c.Assert(graph.Edges, HasLen, 3)
+
+	dot := graph.Dot()
+	c.Assert(strings.HasPrefix(dot, `digraph "graph" {`), Equals, true)
+	c.Assert(strings.Contains(dot, `merge (merge)\nin=4 out=4 err=0`), Equals, true)
+	c.Assert(strings.Contains(dot, "Stage0 -> Stage2;"), Equals, true)
+	c.Assert(strings.Contains(dot, "Stage1 -> Stage2;"), Equals, true)
+	c.Assert(strings.Contains(dot, "Stage2 -> Stage3;"), Equals, true)
+}
+
+func (s *PipelineSuite) Test_Pipeline_Report(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	p := NewPipeline(ctx, "report")
+	p.Report(time.Millisecond)
+
+	Sink(From(p, "source", Generate(nil, 1, 2, 3)), "sink", func(context.Context, int) error {
+		return nil
+	})
+	c.Assert(p.Wait(), IsNil)
+	cancel()
+}
@@ -0,0 +1,249 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// TokenBucket rate limiter, rate tokens are added per second up to burst.
+// Safe to share between goroutines.
+type TokenBucket struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// NewTokenBucket create a full bucket, panics if rps is not positive
+func NewTokenBucket(rps float64, burst int) *TokenBucket {
+	if !(rps > 0) {
+		panic(fmt.Sprintf("streaming: rate must be positive, not %v", rps))
+	}
+	if burst < 1 {
+		burst = 1
+	}
+
+	return &TokenBucket{
+		rate:   rps,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Reserve take n tokens, returns how long the caller must wait before the
+// tokens are available
+func (t *TokenBucket) Reserve(n int) time.Duration {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := time.Now()
+
+	t.tokens += now.Sub(t.last).Seconds() * t.rate
+	if t.tokens > t.burst {
+		t.tokens = t.burst
+	}
+	t.last = now
+
+	t.tokens -= float64(n)
+	if t.tokens >= 0 {
+		return 0
+	}
+
+	return time.Duration(-t.tokens / t.rate * float64(time.Second))
+}
+
+// Wait take n tokens, blocking until they are available or ctx is cancelled.
+// The tokens are returned to the bucket if ctx is cancelled first.
+func (t *TokenBucket) Wait(ctx context.Context, n int) error {
+	wait := t.Reserve(n)
+	if wait <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		t.refund(n)
+		return ctx.Err()
+	}
+}
+
+// refund return n tokens taken by Reserve
+func (t *TokenBucket) refund(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.tokens = min(t.tokens+float64(n), t.burst)
+}
+
+// RateLimit pass items through at no more than rps per second, allowing
+// bursts of up to burst items. Panics if rps is not positive.
+func RateLimit[T any](ctx context.Context, in <-chan T, rps float64, burst int) <-chan T {
+	return RateLimitBy(ctx, in, rps, burst, func(T) int { return 1 })
+}
+
+// RateLimitBy pass items through at no more than rps units per second, where
+// costFn returns the units an item consumes (eg records in a batch)
+func RateLimitBy[T any](ctx context.Context, in <-chan T, rps float64, burst int, costFn func(T) int) <-chan T {
+	bucket := NewTokenBucket(rps, burst)
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					return
+				}
+
+				if err := bucket.Wait(ctx, costFn(v)); err != nil {
+					return
+				}
+
+				select {
+				case out <- v:
+				case <-ctx.Done():
+					return
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Throttle emit at most one item per interval, the latest item received
+// during the interval is kept and earlier ones are dropped. A pending item
+// is emitted when in is closed. Panics if interval is not positive.
+func Throttle[T any](ctx context.Context, in <-chan T, interval time.Duration) <-chan T {
+	mustBePositive("throttle interval", interval)
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var latest T
+		var pending bool
+
+		send := func() bool {
+			if !pending {
+				return true
+			}
+
+			select {
+			case out <- latest:
+				pending = false
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					send()
+					return
+				}
+				latest = v
+				pending = true
+
+			case <-ticker.C:
+				if !send() {
+					return
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Debounce emit an item only once no newer item has arrived for wait. A
+// pending item is emitted when in is closed. Panics if wait is not positive.
+func Debounce[T any](ctx context.Context, in <-chan T, wait time.Duration) <-chan T {
+	mustBePositive("debounce wait", wait)
+	out := make(chan T)
+
+	go func() {
+		defer close(out)
+
+		timer := time.NewTimer(wait)
+		timer.Stop()
+		defer timer.Stop()
+
+		var latest T
+		var pending bool
+
+		send := func() bool {
+			if !pending {
+				return true
+			}
+
+			select {
+			case out <- latest:
+				pending = false
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		for {
+			select {
+			case v, ok := <-in:
+				if !ok {
+					send()
+					return
+				}
+				latest = v
+				pending = true
+
+				timer.Stop()
+				select {
+				case <-timer.C:
+				default:
+				}
+				timer.Reset(wait)
+
+			case <-timer.C:
+				if !send() {
+					return
+				}
+
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// mustBePositive panic if d is not positive, checked when a stage is built
+// so the caller sees it rather than the stage goroutine
+func mustBePositive(name string, d time.Duration) {
+	if d <= 0 {
+		panic(fmt.Sprintf("streaming: %s must be positive, not %v", name, d))
+	}
+}
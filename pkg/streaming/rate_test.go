@@ -0,0 +1,113 @@
+package streaming
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type RateSuite struct{}
+
+var _ = Suite(&RateSuite{})
+
+func (s *RateSuite) Test_TokenBucket(c *C) {
+	bucket := NewTokenBucket(10, 2)
+
+	c.Assert(bucket.Reserve(1), Equals, time.Duration(0))
+	c.Assert(bucket.Reserve(1), Equals, time.Duration(0))
+
+	wait := bucket.Reserve(1)
+	c.Assert(wait > 50*time.Millisecond, Equals, true)
+	c.Assert(wait <= 100*time.Millisecond, Equals, true)
+
+	c.Assert(func() { NewTokenBucket(0, 1) }, PanicMatches, "streaming: rate must be positive, not 0")
+	c.Assert(func() { RateLimit(context.Background(), make(chan int), -1, 1) }, PanicMatches, "streaming: rate must be positive, not -1")
+}
+
+func (s *RateSuite) Test_TokenBucket_WaitCancel(c *C) {
+	bucket := NewTokenBucket(1, 1)
+	c.Assert(bucket.Wait(context.Background(), 1), IsNil)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	c.Assert(bucket.Wait(ctx, 1), Equals, context.DeadlineExceeded)
+
+	// The cancelled wait gave its token back so the next is about a second away
+	wait := bucket.Reserve(1)
+	c.Assert(wait > 900*time.Millisecond, Equals, true)
+	c.Assert(wait <= time.Second, Equals, true)
+}
+
+func (s *RateSuite) Test_RateLimit(c *C) {
+	ctx := context.Background()
+
+	start := time.Now()
+	out := RateLimit(ctx, GenerateContext(ctx, sequence(6)...), 100, 1)
+	c.Assert(Gather(out), DeepEquals, sequence(6))
+
+	// burst of 1 then 5 items at 10ms each
+	c.Assert(time.Since(start) >= 45*time.Millisecond, Equals, true)
+}
+
+func (s *RateSuite) Test_RateLimit_Burst(c *C) {
+	ctx := context.Background()
+
+	start := time.Now()
+	out := RateLimit(ctx, GenerateContext(ctx, sequence(5)...), 1, 5)
+	c.Assert(Gather(out), HasLen, 5)
+	c.Assert(time.Since(start) < 500*time.Millisecond, Equals, true)
+}
+
+func (s *RateSuite) Test_RateLimit_Cancel(c *C) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	out := RateLimit(ctx, GenerateContext(ctx, sequence(5)...), 0.1, 1)
+	c.Assert(<-out, Equals, 0)
+
+	cancel()
+	for _ = range out {
+		c.Fail()
+	}
+}
+
+func (s *RateSuite) Test_Throttle(c *C) {
+	ctx := context.Background()
+	in := make(chan int)
+
+	out := Throttle(ctx, in, 30*time.Millisecond)
+
+	in <- 1
+	in <- 2
+	in <- 3
+	c.Assert(<-out, Equals, 3)
+
+	in <- 4
+	close(in)
+	c.Assert(Gather(out), DeepEquals, []int{4})
+}
+
+func (s *RateSuite) Test_Throttle_Invalid(c *C) {
+	in := make(chan int)
+	c.Assert(func() { Throttle(context.Background(), in, 0) }, PanicMatches, "streaming: throttle interval must be positive, not 0s")
+	c.Assert(func() { Debounce(context.Background(), in, -time.Second) }, PanicMatches, "streaming: debounce wait must be positive, not -1s")
+}
+
+func (s *RateSuite) Test_Debounce(c *C) {
+	ctx := context.Background()
+	in := make(chan int)
+
+	out := Debounce(ctx, in, 30*time.Millisecond)
+
+	go func() {
+		defer close(in)
+		in <- 1
+		in <- 2
+		in <- 3
+		time.Sleep(100 * time.Millisecond)
+		in <- 4
+		in <- 5
+	}()
+
+	c.Assert(Gather(out), DeepEquals, []int{3, 5})
+}
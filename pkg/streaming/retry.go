@@ -0,0 +1,176 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff exponential backoff policy
+// Initial    - delay before the first retry, DefaultBackoff.Initial if <= 0
+// Max        - upper bound on any delay
+// Multiplier - growth of the delay per attempt
+// Jitter     - fraction of the delay randomised, 0.2 gives delay ±20%
+// Attempts   - total attempts including the first, 0 retries until ctx is done
+type Backoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+	Jitter     float64
+	Attempts   int
+}
+
+var DefaultBackoff = Backoff{
+	Initial:    100 * time.Millisecond,
+	Max:        10 * time.Second,
+	Multiplier: 1.5,
+	Jitter:     0.2,
+	Attempts:   5,
+}
+
+// Delay returns the wait before retry number attempt (starting at 1)
+func (t Backoff) Delay(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	multiplier := t.Multiplier
+	if multiplier < 1 {
+		multiplier = 1
+	}
+
+	initial := t.Initial
+	if initial <= 0 {
+		initial = DefaultBackoff.Initial
+	}
+
+	delay := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if t.Max > 0 && delay > float64(t.Max) {
+		delay = float64(t.Max)
+	}
+
+	if t.Jitter > 0 {
+		delay += delay * t.Jitter * (2*rand.Float64() - 1)
+	}
+
+	return time.Duration(delay)
+}
+
+type permanentError struct {
+	err error
+}
+
+func (t *permanentError) Error() string {
+	return t.err.Error()
+}
+
+func (t *permanentError) Unwrap() error {
+	return t.err
+}
+
+// Permanent marks err as not worth retrying
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err}
+}
+
+// IsPermanent whether err was marked with Permanent
+func IsPermanent(err error) bool {
+	var perm *permanentError
+	return errors.As(err, &perm)
+}
+
+// DeadLetter an item that failed every attempt together with the last error
+type DeadLetter[T any] struct {
+	Item     T
+	Err      error
+	Attempts int
+}
+
+// RetryFunc call fn until it succeeds, returns a permanent error, the
+// attempts are exhausted or ctx is cancelled. Returns the number of attempts
+// made and the last error.
+func RetryFunc(ctx context.Context, backoff Backoff, fn func(context.Context) error) (int, error) {
+	var attempt int
+	for {
+		attempt++
+
+		err := fn(ctx)
+		if err == nil {
+			return attempt, nil
+		}
+
+		if IsPermanent(err) {
+			return attempt, err
+		}
+
+		if backoff.Attempts > 0 && attempt >= backoff.Attempts {
+			return attempt, err
+		}
+
+		timer := time.NewTimer(backoff.Delay(attempt))
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempt, errors.Join(err, ctx.Err())
+		}
+	}
+}
+
+// Retry apply fn to every item retrying failures with backoff. Items that
+// still fail are sent to the dead-letter channel with their final error.
+// Items are retried one at a time, run several Retry stages or wrap fn with
+// RetryFunc inside ParallelMap for concurrency. The dead-letter channel must
+// be consumed.
+func Retry[I any, O any](ctx context.Context, in <-chan I, backoff Backoff, fn func(context.Context, I) (O, error)) (<-chan O, <-chan DeadLetter[I]) {
+	out := make(chan O)
+	deadCh := make(chan DeadLetter[I])
+
+	go func() {
+		defer close(out)
+		defer close(deadCh)
+
+		for {
+			var v I
+			var ok bool
+
+			select {
+			case v, ok = <-in:
+				if !ok {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+
+			var o O
+			attempts, err := RetryFunc(ctx, backoff, func(ctx context.Context) error {
+				var err error
+				o, err = fn(ctx, v)
+				return err
+			})
+
+			if err != nil {
+				select {
+				case deadCh <- DeadLetter[I]{Item: v, Err: err, Attempts: attempts}:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+
+			select {
+			case out <- o:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out, deadCh
+}
@@ -0,0 +1,116 @@
+package streaming
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type RetrySuite struct{}
+
+var _ = Suite(&RetrySuite{})
+
+var fastBackoff = Backoff{
+	Initial:    time.Millisecond,
+	Max:        5 * time.Millisecond,
+	Multiplier: 2,
+	Jitter:     0.1,
+	Attempts:   3,
+}
+
+func (s *RetrySuite) Test_Backoff_Delay(c *C) {
+	backoff := Backoff{
+		Initial:    100 * time.Millisecond,
+		Max:        time.Second,
+		Multiplier: 2,
+	}
+
+	c.Assert(backoff.Delay(1), Equals, 100*time.Millisecond)
+	c.Assert(backoff.Delay(2), Equals, 200*time.Millisecond)
+	c.Assert(backoff.Delay(3), Equals, 400*time.Millisecond)
+	c.Assert(backoff.Delay(10), Equals, time.Second)
+
+	backoff.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		d := backoff.Delay(1)
+		c.Assert(d >= 50*time.Millisecond && d <= 150*time.Millisecond, Equals, true)
+	}
+
+	// A zero Initial must not retry in a tight loop
+	c.Assert(Backoff{}.Delay(1), Equals, DefaultBackoff.Initial)
+}
+
+func (s *RetrySuite) Test_Retry(c *C) {
+	ctx := context.Background()
+
+	var mu sync.Mutex
+	calls := make(map[int]int)
+
+	// 1 succeeds first time, 2 succeeds on the third attempt, 3 never succeeds
+	fn := func(_ context.Context, i int) (int, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		calls[i]++
+		if i == 1 || (i == 2 && calls[i] == 3) {
+			return i * 10, nil
+		}
+		return 0, errors.New("failed")
+	}
+
+	out, deadCh := Retry(ctx, GenerateContext(ctx, 1, 2, 3), fastBackoff, fn)
+
+	deadLetters := make(chan []DeadLetter[int])
+	go func() {
+		deadLetters <- Gather(deadCh)
+	}()
+
+	c.Assert(Gather(out), DeepEquals, []int{10, 20})
+
+	dead := <-deadLetters
+	c.Assert(dead, HasLen, 1)
+	c.Assert(dead[0].Item, Equals, 3)
+	c.Assert(dead[0].Attempts, Equals, 3)
+	c.Assert(dead[0].Err, ErrorMatches, "failed")
+
+	c.Assert(calls[1], Equals, 1)
+	c.Assert(calls[2], Equals, 3)
+	c.Assert(calls[3], Equals, 3)
+}
+
+func (s *RetrySuite) Test_Retry_Permanent(c *C) {
+	ctx := context.Background()
+
+	var calls int
+	fn := func(_ context.Context, i int) (int, error) {
+		calls++
+		return 0, Permanent(errors.New("bad input"))
+	}
+
+	out, deadCh := Retry(ctx, GenerateContext(ctx, 1), fastBackoff, fn)
+	go Consume(out)
+
+	dead := <-deadCh
+	c.Assert(dead.Attempts, Equals, 1)
+	c.Assert(IsPermanent(dead.Err), Equals, true)
+	c.Assert(calls, Equals, 1)
+}
+
+func (s *RetrySuite) Test_RetryFunc_Cancel(c *C) {
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	backoff := Backoff{Initial: time.Hour}
+
+	failure := errors.New("failed")
+	attempts, err := RetryFunc(ctx, backoff, func(context.Context) error {
+		return failure
+	})
+
+	c.Assert(attempts, Equals, 1)
+	c.Assert(errors.Is(err, failure), Equals, true)
+	c.Assert(errors.Is(err, context.DeadlineExceeded), Equals, true)
+}
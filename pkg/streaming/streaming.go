@@ -1,6 +1,7 @@
 package streaming
 
 import (
+	"errors"
 	"log"
 	"sync"
 	"time"
@@ -13,9 +14,7 @@ func Apply[T any](done <-chan struct{}, ch <-chan T, fn func(T) T) <-chan T {
 		defer close(out)
 
 		for v := range ch {
-			select {
-			case out <- fn(v):
-			case <-done:
+			if !sendDone(done, out, fn(v)) {
 				return
 			}
 		}
@@ -37,9 +36,7 @@ func Morph[I any, O any](done <-chan struct{}, ch <-chan I, fn func(I) (O, error
 				continue
 			}
 
-			select {
-			case out <- o:
-			case <-done:
+			if !sendDone(done, out, o) {
 				return
 			}
 		}
@@ -91,9 +88,7 @@ func MergeDone[T any](done <-chan struct{}, ch ...<-chan T) <-chan T {
 		defer wg.Done()
 
 		for v := range ch {
-			select {
-			case out <- v:
-			case <-done:
+			if !sendDone(done, out, v) {
 				return
 			}
 		}
@@ -196,9 +191,7 @@ func Generate[T any](done <-chan struct{}, v ...T) <-chan T {
 	go func() {
 		defer close(out)
 		for _, n := range v {
-			select {
-			case out <- n:
-			case <-done:
+			if !sendDone(done, out, n) {
 				return
 			}
 		}
@@ -327,12 +320,18 @@ func ErrorLog(in <-chan error) {
 	}()
 }
 
-func Copy[T any](out chan<- T, in <-chan T) {
+// Copy forward in to out until in is closed. out is left open as several
+// inputs may share it, the returned channel is closed once in is drained so
+// the owner of out knows when it is safe to close.
+func Copy[T any](out chan<- T, in <-chan T) <-chan struct{} {
+	drained := make(chan struct{})
 	go func() {
+		defer close(drained)
 		for v := range in {
 			out <- v
 		}
 	}()
+	return drained
 }
 
 func CopyWG[T any](wg *sync.WaitGroup, out chan<- T, in <-chan T) {
@@ -345,28 +344,29 @@ func CopyWG[T any](wg *sync.WaitGroup, out chan<- T, in <-chan T) {
 	}()
 }
 
+// Loop emit data repeatedly until done is closed
 func Loop[T any](done <-chan struct{}, data []T) (<-chan T, error) {
+	if len(data) == 0 {
+		return nil, errors.New("loop requires data")
+	}
+
 	out := make(chan T)
 	go func() {
 		defer close(out)
 
 		var index int
-		for {
-			select {
-			case <-done:
-				return
-			default:
-				out <- data[index]
-
-				index = (index + 1) % len(data)
-			}
+		for sendDone(done, out, data[index]) {
+			index = (index + 1) % len(data)
 		}
-
 	}()
 
 	return out, nil
 }
 
+// Batch group items into slices of batchSize, a partial batch is emitted
+// after timeout or when inCh closes. Closing doneCh stops the stage, the
+// final partial batch is offered to the consumer for up to timeout and then
+// dropped. Each batch is a new slice that is safe to keep.
 func Batch[T any](doneCh <-chan struct{}, inCh <-chan T, batchSize int, timeout time.Duration) <-chan []T {
 	outCh := make(chan []T)
 
@@ -374,34 +374,39 @@ func Batch[T any](doneCh <-chan struct{}, inCh <-chan T, batchSize int, timeout
 		defer close(outCh)
 		batch := make([]T, 0, batchSize)
 		timer := time.NewTimer(timeout)
+		defer timer.Stop()
 
 		for {
 			select {
 			case <-doneCh:
 				if len(batch) > 0 {
-					outCh <- batch
+					flushDone(doneCh, outCh, batch, timeout)
 				}
 				return
 
 			case item, ok := <-inCh:
 				if !ok {
 					if len(batch) > 0 {
-						outCh <- batch
+						flushDone(doneCh, outCh, batch, timeout)
 					}
 					return
 				}
 
 				batch = append(batch, item)
 				if len(batch) == batchSize {
-					outCh <- batch
-					batch = batch[:0] // make([]T, 0, batchSize)
+					if !sendDone(doneCh, outCh, batch) {
+						return
+					}
+					batch = make([]T, 0, batchSize)
 					timer.Reset(timeout)
 				}
 			case <-timer.C:
 				// Timeout, send the current batch even if it's not full
 				if len(batch) > 0 {
-					outCh <- batch
-					batch = batch[:0] // make([]T, 0, batchSize)
+					if !sendDone(doneCh, outCh, batch) {
+						return
+					}
+					batch = make([]T, 0, batchSize)
 				}
 				timer.Reset(timeout)
 			}
@@ -409,3 +414,38 @@ func Batch[T any](doneCh <-chan struct{}, inCh <-chan T, batchSize int, timeout
 	}()
 	return outCh
 }
+
+// flushDone send the final v to out, once done is closed the consumer has
+// timeout to take it before it is dropped
+func flushDone[T any](done <-chan struct{}, out chan<- T, v T, timeout time.Duration) {
+	select {
+	case out <- v:
+		return
+	case <-done:
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case out <- v:
+	case <-timer.C:
+	}
+}
+
+// sendDone send v to out unless done is closed. done is checked first so a
+// closed done wins over a ready receiver.
+func sendDone[T any](done <-chan struct{}, out chan<- T, v T) bool {
+	select {
+	case <-done:
+		return false
+	default:
+	}
+
+	select {
+	case out <- v:
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -217,23 +217,94 @@ func (s *StreamingSuite) Test_Or(c *C) {
 }
 
 func (s *StreamingSuite) Test_FanOut(c *C) {
-	done := make(chan struct{})
-
-	gen := Generate[int](done, 1, 2, 3, 4, 5)
+	gen := Generate[int](nil, 1, 2, 3, 4, 5)
 
 	streams := FanOut[int](gen, 3)
 
+	var wg sync.WaitGroup
 	fn := func(ch chan int) {
-		c.Assert(<-ch, Equals, 1)
-		c.Assert(<-ch, Equals, 2)
-		c.Assert(<-ch, Equals, 3)
-		c.Assert(<-ch, Equals, 4)
-		c.Assert(<-ch, Equals, 5)
+		defer wg.Done()
+		c.Check(Gather(ch), DeepEquals, []int{1, 2, 3, 4, 5})
 	}
 
+	wg.Add(len(streams))
 	for _, ch := range streams {
 		go fn(ch)
 	}
+	wg.Wait()
+}
+
+func (s *StreamingSuite) Test_Loop(c *C) {
+	done := make(chan struct{})
+
+	out, err := Loop(done, []int{1, 2})
+	c.Assert(err, IsNil)
+	c.Assert(<-out, Equals, 1)
+	c.Assert(<-out, Equals, 2)
+	c.Assert(<-out, Equals, 1)
+
+	// Loop must stop even though nothing is reading
+	close(done)
+	time.Sleep(time.Millisecond)
+	for _ = range out {
+	}
+
+	_, err = Loop(done, []int{})
+	c.Assert(err, NotNil)
+}
+
+func (s *StreamingSuite) Test_Copy(c *C) {
+	out := make(chan int, 6)
+
+	a := Copy(out, Generate(nil, 1, 2, 3))
+	b := Copy(out, Generate(nil, 4, 5, 6))
+	<-AllDone(a, b)
+	close(out)
 
+	c.Assert(Gather(out), HasLen, 6)
+}
+
+func (s *StreamingSuite) Test_Batch(c *C) {
+	out := Batch(nil, Generate(nil, 1, 2, 3, 4, 5), 2, time.Hour)
+
+	first := <-out
+	second := <-out
+	c.Assert(first, DeepEquals, []int{1, 2})
+	c.Assert(second, DeepEquals, []int{3, 4})
+	c.Assert(<-out, DeepEquals, []int{5})
+}
+
+func (s *StreamingSuite) Test_Batch_Done(c *C) {
+	done := make(chan struct{})
+	in := make(chan int)
+
+	out := Batch(done, in, 10, time.Hour)
+	in <- 1
+	in <- 2
+
+	// The partial batch is flushed before the output closes
 	close(done)
+	c.Assert(<-out, DeepEquals, []int{1, 2})
+
+	_, ok := <-out
+	c.Assert(ok, Equals, false)
+}
+
+func (s *StreamingSuite) Test_Batch_DoneUnread(c *C) {
+	done := make(chan struct{})
+	in := make(chan int)
+
+	out := Batch(done, in, 10, 20*time.Millisecond)
+	in <- 1
+
+	// The consumer has gone, the batch is dropped once the flush deadline passes
+	close(done)
+	time.Sleep(50 * time.Millisecond)
+
+	select {
+	case _, ok := <-out:
+		c.Assert(ok, Equals, false)
+	case <-time.After(5 * time.Second):
+		c.Fatal("batch blocked on an unread output")
+	}
 }
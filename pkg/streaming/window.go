@@ -0,0 +1,345 @@
+package streaming
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type windowKind int
+
+const (
+	tumblingWindow windowKind = iota
+	slidingWindow
+	sessionWindow
+)
+
+// WindowSpec describes how items are assigned to windows, create with
+// Tumbling, Sliding or Session
+type WindowSpec struct {
+	kind  windowKind
+	size  time.Duration
+	slide time.Duration
+	gap   time.Duration
+}
+
+// Tumbling fixed size, non-overlapping windows. Panics if size is not
+// positive.
+func Tumbling(size time.Duration) WindowSpec {
+	spec := WindowSpec{kind: tumblingWindow, size: size, slide: size}
+	spec.mustValidate()
+	return spec
+}
+
+// Sliding fixed size windows starting every slide, an item belongs to every
+// window that covers it. Panics if size or slide is not positive.
+func Sliding(size, slide time.Duration) WindowSpec {
+	spec := WindowSpec{kind: slidingWindow, size: size, slide: slide}
+	spec.mustValidate()
+	return spec
+}
+
+// Session windows grow while items keep arriving and close once no item has
+// been seen for gap. Panics if gap is not positive.
+func Session(gap time.Duration) WindowSpec {
+	spec := WindowSpec{kind: sessionWindow, gap: gap}
+	spec.mustValidate()
+	return spec
+}
+
+// mustValidate panic on a duration that would never advance a window, the
+// zero WindowSpec is invalid
+func (t WindowSpec) mustValidate() {
+	switch t.kind {
+	case sessionWindow:
+		if t.gap <= 0 {
+			panic(fmt.Sprintf("session gap must be positive, not %v", t.gap))
+		}
+	default:
+		if t.size <= 0 {
+			panic(fmt.Sprintf("window size must be positive, not %v", t.size))
+		}
+		if t.slide <= 0 {
+			panic(fmt.Sprintf("window slide must be positive, not %v", t.slide))
+		}
+	}
+}
+
+// LatePolicy defines what happens to items that arrive after their window
+// has already been emitted
+type LatePolicy int
+
+const (
+	// LateDrop discard late items
+	LateDrop LatePolicy = iota
+
+	// LateSideOutput send late items on the late channel
+	LateSideOutput
+)
+
+// WindowOptions controls how time is measured for Aggregate. EventTime
+// extracts the event time of an item, nil uses processing time. Lateness is
+// how far event time may run behind the latest event seen before an item is
+// considered late, it is ignored for processing time.
+type WindowOptions[T any] struct {
+	EventTime func(T) time.Time
+	Lateness  time.Duration
+	Late      LatePolicy
+}
+
+// Windowed result of reducing all items in a window
+type Windowed[R any] struct {
+	Start time.Time
+	End   time.Time
+	Count int
+	Value R
+}
+
+// windowState an open window, sessions keep their items and the time of
+// each in timestamp order until they are emitted
+type windowState[T any, R any] struct {
+	start time.Time
+	end   time.Time
+	count int
+	acc   R
+	items []T
+	times []time.Time
+}
+
+// mergeItems merge the items of two sessions, each in timestamp order, into
+// one in timestamp order. Items of a come first on equal timestamps.
+func mergeItems[T any](a []T, aTimes []time.Time, b []T, bTimes []time.Time) ([]T, []time.Time) {
+	items := make([]T, 0, len(a)+len(b))
+	times := make([]time.Time, 0, len(a)+len(b))
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if j == len(b) || (i < len(a) && !bTimes[j].Before(aTimes[i])) {
+			items = append(items, a[i])
+			times = append(times, aTimes[i])
+			i++
+		} else {
+			items = append(items, b[j])
+			times = append(times, bTimes[j])
+			j++
+		}
+	}
+	return items, times
+}
+
+// Aggregate reduce items into windows. A window is emitted once the
+// watermark passes its end: with processing time the watermark is the wall
+// clock, with event time it is the latest event time seen less Lateness.
+// Open windows are emitted when in is closed and discarded when ctx is
+// cancelled. The late channel only receives items with LateSideOutput but
+// must be consumed in that case. Panics if spec is the zero WindowSpec.
+func Aggregate[T any, R any](ctx context.Context, in <-chan T, spec WindowSpec, opts WindowOptions[T], reduce func(R, T) R) (<-chan Windowed[R], <-chan T) {
+	spec.mustValidate()
+
+	out := make(chan Windowed[R])
+	lateCh := make(chan T)
+
+	go func() {
+		defer close(out)
+		defer close(lateCh)
+
+		var windows []*windowState[T, R]
+		var watermark time.Time
+
+		timer := time.NewTimer(time.Hour)
+		timer.Stop()
+		defer timer.Stop()
+
+		emit := func(w *windowState[T, R]) bool {
+			result := Windowed[R]{
+				Start: w.start,
+				End:   w.end,
+				Count: w.count,
+				Value: w.acc,
+			}
+
+			if spec.kind == sessionWindow {
+				for _, item := range w.items {
+					result.Value = reduce(result.Value, item)
+				}
+			}
+
+			select {
+			case out <- result:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		// fire emits every window that ends at or before the watermark
+		fire := func(all bool) bool {
+			sort.Slice(windows, func(i, j int) bool {
+				return windows[i].end.Before(windows[j].end)
+			})
+
+			var n int
+			for _, w := range windows {
+				if !all && w.end.After(watermark) {
+					break
+				}
+				if !emit(w) {
+					return false
+				}
+				n++
+			}
+			windows = windows[n:]
+			return true
+		}
+
+		schedule := func() {
+			if opts.EventTime != nil || len(windows) == 0 {
+				return
+			}
+
+			earliest := windows[0].end
+			for _, w := range windows {
+				if w.end.Before(earliest) {
+					earliest = w.end
+				}
+			}
+
+			timer.Stop()
+			select {
+			case <-timer.C:
+			default:
+			}
+			timer.Reset(time.Until(earliest))
+		}
+
+		late := func(item T) bool {
+			if opts.Late != LateSideOutput {
+				return true
+			}
+
+			select {
+			case lateCh <- item:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+
+		add := func(item T, ts time.Time) bool {
+			switch spec.kind {
+			case sessionWindow:
+				if !watermark.IsZero() && !ts.Add(spec.gap).After(watermark) {
+					return late(item)
+				}
+
+				session := &windowState[T, R]{
+					start: ts,
+					end:   ts.Add(spec.gap),
+					count: 1,
+					items: []T{item},
+					times: []time.Time{ts},
+				}
+
+				// Merge every open session the new item overlaps
+				merged := windows[:0]
+				for _, w := range windows {
+					if w.start.After(session.end) || session.start.After(w.end) {
+						merged = append(merged, w)
+						continue
+					}
+
+					if w.start.Before(session.start) {
+						session.start = w.start
+					}
+					if w.end.After(session.end) {
+						session.end = w.end
+					}
+					session.count += w.count
+					session.items, session.times = mergeItems(w.items, w.times, session.items, session.times)
+				}
+				windows = append(merged, session)
+
+			default:
+				var assigned, dropped int
+
+				last := ts.Truncate(spec.slide)
+				for start := last; start.Add(spec.size).After(ts); start = start.Add(-spec.slide) {
+					end := start.Add(spec.size)
+					if !watermark.IsZero() && !end.After(watermark) {
+						dropped++
+						continue
+					}
+					assigned++
+
+					var w *windowState[T, R]
+					for _, ww := range windows {
+						if ww.start.Equal(start) {
+							w = ww
+							break
+						}
+					}
+
+					if w == nil {
+						w = &windowState[T, R]{start: start, end: end}
+						windows = append(windows, w)
+					}
+
+					w.count++
+					w.acc = reduce(w.acc, item)
+				}
+
+				if assigned == 0 && dropped > 0 {
+					return late(item)
+				}
+			}
+
+			return true
+		}
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+
+			case item, ok := <-in:
+				if !ok {
+					fire(true)
+					return
+				}
+
+				var ts time.Time
+				if opts.EventTime != nil {
+					ts = opts.EventTime(item)
+				} else {
+					ts = time.Now()
+					watermark = ts
+				}
+
+				if !add(item, ts) {
+					return
+				}
+
+				if opts.EventTime != nil {
+					if wm := ts.Add(-opts.Lateness); wm.After(watermark) {
+						watermark = wm
+					}
+				}
+
+				if !fire(false) {
+					return
+				}
+				schedule()
+
+			case now := <-timer.C:
+				watermark = now
+				if !fire(false) {
+					return
+				}
+				schedule()
+			}
+		}
+	}()
+
+	return out, lateCh
+}
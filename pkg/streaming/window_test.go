@@ -0,0 +1,198 @@
+package streaming
+
+import (
+	"context"
+	"time"
+
+	. "gopkg.in/check.v1"
+)
+
+type WindowSuite struct{}
+
+var _ = Suite(&WindowSuite{})
+
+type reading struct {
+	at    time.Time
+	value int
+}
+
+var epoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func readings(seconds ...int) []reading {
+	arr := make([]reading, len(seconds))
+	for i, sec := range seconds {
+		arr[i] = reading{at: epoch.Add(time.Duration(sec) * time.Second), value: 1}
+	}
+	return arr
+}
+
+func sumReading(acc int, r reading) int {
+	return acc + r.value
+}
+
+func readingTime(r reading) time.Time {
+	return r.at
+}
+
+func (s *WindowSuite) Test_Tumbling(c *C) {
+	ctx := context.Background()
+	in := GenerateContext(ctx, readings(0, 1, 9, 10, 15, 25)...)
+
+	out, late := Aggregate(ctx, in, Tumbling(10*time.Second), WindowOptions[reading]{
+		EventTime: readingTime,
+	}, sumReading)
+	go Consume(late)
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 3)
+	c.Assert(results[0].Start, Equals, epoch)
+	c.Assert(results[0].Value, Equals, 3)
+	c.Assert(results[1].Start, Equals, epoch.Add(10*time.Second))
+	c.Assert(results[1].Value, Equals, 2)
+	c.Assert(results[2].End, Equals, epoch.Add(30*time.Second))
+	c.Assert(results[2].Value, Equals, 1)
+}
+
+func (s *WindowSuite) Test_Sliding(c *C) {
+	ctx := context.Background()
+	in := GenerateContext(ctx, readings(0, 5, 12)...)
+
+	out, late := Aggregate(ctx, in, Sliding(10*time.Second, 5*time.Second), WindowOptions[reading]{
+		EventTime: readingTime,
+	}, sumReading)
+	go Consume(late)
+
+	counts := make(map[time.Time]int)
+	for w := range out {
+		counts[w.Start] = w.Value
+	}
+
+	c.Assert(counts[epoch.Add(-5*time.Second)], Equals, 1)
+	c.Assert(counts[epoch], Equals, 2)
+	c.Assert(counts[epoch.Add(5*time.Second)], Equals, 2)
+	c.Assert(counts[epoch.Add(10*time.Second)], Equals, 1)
+}
+
+func (s *WindowSuite) Test_Session(c *C) {
+	ctx := context.Background()
+	in := GenerateContext(ctx, readings(0, 2, 4, 20, 21, 50)...)
+
+	out, late := Aggregate(ctx, in, Session(5*time.Second), WindowOptions[reading]{
+		EventTime: readingTime,
+	}, sumReading)
+	go Consume(late)
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 3)
+	c.Assert(results[0].Value, Equals, 3)
+	c.Assert(results[0].End, Equals, epoch.Add(9*time.Second))
+	c.Assert(results[1].Value, Equals, 2)
+	c.Assert(results[2].Value, Equals, 1)
+}
+
+func (s *WindowSuite) Test_Session_OutOfOrder(c *C) {
+	ctx := context.Background()
+	in := GenerateContext(ctx, readings(0, 8, 4)...)
+
+	out, late := Aggregate(ctx, in, Session(5*time.Second), WindowOptions[reading]{
+		EventTime: readingTime,
+		Lateness:  10 * time.Second,
+	}, sumReading)
+	go Consume(late)
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 1)
+	c.Assert(results[0].Count, Equals, 3)
+}
+
+func (s *WindowSuite) Test_Session_Order(c *C) {
+	ctx := context.Background()
+	in := GenerateContext(ctx, readings(0, 8, 4, 2, 6)...)
+
+	out, late := Aggregate(ctx, in, Session(5*time.Second), WindowOptions[reading]{
+		EventTime: readingTime,
+		Lateness:  10 * time.Second,
+	}, func(acc []int, r reading) []int {
+		return append(acc, int(r.at.Sub(epoch)/time.Second))
+	})
+	go Consume(late)
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 1)
+	c.Assert(results[0].Value, DeepEquals, []int{0, 2, 4, 6, 8})
+}
+
+func (s *WindowSuite) Test_InvalidSpec(c *C) {
+	c.Assert(func() { Tumbling(0) }, PanicMatches, "window size must be positive, not 0s")
+	c.Assert(func() { Sliding(time.Second, -time.Second) }, PanicMatches, "window slide must be positive, not -1s")
+	c.Assert(func() { Session(0) }, PanicMatches, "session gap must be positive, not 0s")
+
+	in := make(chan int)
+	c.Assert(func() {
+		Aggregate(context.Background(), in, WindowSpec{}, WindowOptions[int]{}, func(acc, v int) int {
+			return acc + v
+		})
+	}, PanicMatches, "window size must be positive, not 0s")
+}
+
+func (s *WindowSuite) Test_Late_SideOutput(c *C) {
+	ctx := context.Background()
+	in := GenerateContext(ctx, readings(0, 11, 3, 22)...)
+
+	out, late := Aggregate(ctx, in, Tumbling(10*time.Second), WindowOptions[reading]{
+		EventTime: readingTime,
+		Late:      LateSideOutput,
+	}, sumReading)
+
+	lateCh := make(chan []reading)
+	go func() {
+		lateCh <- Gather(late)
+	}()
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 3)
+	c.Assert(results[0].Value, Equals, 1)
+
+	lateItems := <-lateCh
+	c.Assert(lateItems, HasLen, 1)
+	c.Assert(lateItems[0].at, Equals, epoch.Add(3*time.Second))
+}
+
+func (s *WindowSuite) Test_Late_Lateness(c *C) {
+	ctx := context.Background()
+	in := GenerateContext(ctx, readings(0, 11, 3, 22)...)
+
+	out, late := Aggregate(ctx, in, Tumbling(10*time.Second), WindowOptions[reading]{
+		EventTime: readingTime,
+		Lateness:  5 * time.Second,
+	}, sumReading)
+	go Consume(late)
+
+	results := Gather(out)
+	c.Assert(results, HasLen, 3)
+	c.Assert(results[0].Value, Equals, 2)
+}
+
+func (s *WindowSuite) Test_ProcessingTime(c *C) {
+	ctx := context.Background()
+	in := make(chan int)
+
+	out, late := Aggregate(ctx, in, Tumbling(20*time.Millisecond), WindowOptions[int]{}, func(acc, v int) int {
+		return acc + v
+	})
+	go Consume(late)
+
+	in <- 1
+	in <- 2
+
+	select {
+	case w := <-out:
+		c.Assert(w.Value >= 1, Equals, true)
+		c.Assert(w.End.Sub(w.Start), Equals, 20*time.Millisecond)
+	case <-time.After(time.Second):
+		c.Fatal("window not emitted")
+	}
+
+	close(in)
+	Consume(out)
+}